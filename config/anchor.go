@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Anchor keeps a monitor attached to another one, so its position follows
+// when either changes size. The zero value means an absolute position.
+type Anchor struct {
+	Target   string
+	Relation Relation
+	Align    Alignment
+}
+
+func (a Anchor) IsSet() bool {
+	return a.Target != ""
+}
+
+func (a Anchor) String() string {
+	return fmt.Sprintf("%s %s, %s aligned", a.Relation, a.Target, a.Align.Name(a.Relation))
+}
+
+// anchorPrefix starts the comment line an anchor is stored in. MangoWC
+// ignores comments, so the config stays valid.
+const anchorPrefix = "# mangomon:anchor="
+
+var relationKeys = []string{"right-of", "left-of", "above", "below"}
+
+// anchorLine formats the anchor of a rule, e.g.
+// "# mangomon:anchor=name:DP-1,target:eDP-1,side:right-of,align:top"
+func anchorLine(r MonitorRule) string {
+	a := r.Anchor
+	return fmt.Sprintf("%sname:%s,target:%s,side:%s,align:%s",
+		anchorPrefix, r.ID, a.Target, relationKeys[a.Relation], a.Align.Name(a.Relation))
+}
+
+// parseAnchor parses the part of an anchor line after the prefix
+func parseAnchor(val string) (string, Anchor, error) {
+	var id string
+	var a Anchor
+	var haveSide, haveAlign bool
+	var align string
+	for _, pair := range strings.Split(val, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return "", Anchor{}, fmt.Errorf("malformed pair %q", pair)
+		}
+		switch k {
+		case "name":
+			id = v
+		case "target":
+			a.Target = v
+		case "side":
+			for i, key := range relationKeys {
+				if key == v {
+					a.Relation, haveSide = Relation(i), true
+				}
+			}
+			if !haveSide {
+				return "", Anchor{}, fmt.Errorf("unknown side %q, want one of %s", v, strings.Join(relationKeys, ", "))
+			}
+		case "align":
+			align, haveAlign = v, true
+		default:
+			return "", Anchor{}, fmt.Errorf("unknown key %q", k)
+		}
+	}
+	if id == "" || a.Target == "" {
+		return "", Anchor{}, fmt.Errorf("anchor needs a name and a target")
+	}
+
+	// Alignment names depend on the side, so resolve them last
+	if haveAlign {
+		found := false
+		for _, al := range []Alignment{AlignStart, AlignCenter, AlignEnd} {
+			if al.Name(a.Relation) == align {
+				a.Align, found = al, true
+			}
+		}
+		if !found {
+			return "", Anchor{}, fmt.Errorf("alignment %q doesn't fit side %s", align, relationKeys[a.Relation])
+		}
+	}
+	return id, a, nil
+}
+
+// Solve recomputes the position of every anchored monitor from its target,
+// targets first, then shifts the layout back to 0,0 if a monitor was placed
+// at negative coordinates.
+// Anchors whose target doesn't exist or that form a loop are left alone and
+// returned.
+func Solve(rules map[string]MonitorRule) (broken []string) {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	status := make(map[string]int)
+	negative := false
+
+	var place func(id string) bool
+	place = func(id string) bool {
+		switch status[id] {
+		case done:
+			return true
+		case visiting:
+			return false // Loop
+		}
+		r := rules[id]
+		if !r.Anchor.IsSet() {
+			status[id] = done
+			return true
+		}
+		status[id] = visiting
+		if _, ok := rules[r.Anchor.Target]; !ok || !place(r.Anchor.Target) {
+			status[id] = done
+			broken = append(broken, id)
+			return true // Keep the absolute position, dependents can still follow
+		}
+		w, h := r.LogicalSize()
+		r.X, r.Y = PlaceRelative(w, h, rules[r.Anchor.Target].Rect(), r.Anchor.Relation, r.Anchor.Align)
+		rules[id] = r
+		status[id] = done
+		negative = negative || r.X < 0 || r.Y < 0
+		return true
+	}
+
+	var ids []string
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		place(id)
+	}
+
+	if negative {
+		Normalize(rules)
+	}
+	sort.Strings(broken)
+	return broken
+}
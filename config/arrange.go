@@ -0,0 +1,168 @@
+package config
+
+import (
+	"math"
+	"sort"
+)
+
+// ArrangeOp is an alignment or distribution command for several monitors
+type ArrangeOp int
+
+const (
+	AlignLeft ArrangeOp = iota
+	AlignRight
+	AlignTop
+	AlignBottom
+	AlignCenterX // Same horizontal center
+	AlignCenterY // Same vertical center
+	DistributeX  // Equal horizontal gaps, outermost monitors stay put
+	DistributeY
+	PackX // Side by side without gaps, starting at the leftmost monitor
+	PackY
+)
+
+// ArrangeOps lists every op in menu order
+var ArrangeOps = []ArrangeOp{
+	AlignLeft, AlignRight, AlignTop, AlignBottom, AlignCenterX, AlignCenterY,
+	DistributeX, DistributeY, PackX, PackY,
+}
+
+var arrangeNames = []string{
+	"Align left edges",
+	"Align right edges",
+	"Align top edges",
+	"Align bottom edges",
+	"Align horizontal centers",
+	"Align vertical centers",
+	"Distribute horizontally",
+	"Distribute vertically",
+	"Pack side by side",
+	"Pack top to bottom",
+}
+
+func (op ArrangeOp) String() string {
+	if op >= 0 && int(op) < len(arrangeNames) {
+		return arrangeNames[op]
+	}
+	return "?"
+}
+
+// Arrange applies op to the given monitors in logical coordinates and then
+// normalizes the layout. Unknown IDs are ignored.
+func Arrange(rules map[string]MonitorRule, ids []string, op ArrangeOp) {
+	var rects []Rect
+	var known []string
+	for _, id := range ids {
+		if r, ok := rules[id]; ok {
+			known = append(known, id)
+			rects = append(rects, r.Rect())
+		}
+	}
+	if len(known) < 2 {
+		return
+	}
+
+	bounds := rects[0]
+	for _, r := range rects[1:] {
+		bounds = bounds.Union(r)
+	}
+
+	move := func(i, x, y int) {
+		rule := rules[known[i]]
+		rule.X, rule.Y = x, y
+		rules[known[i]] = rule
+		rects[i].X, rects[i].Y = x, y
+	}
+
+	switch op {
+	case AlignLeft:
+		for i, r := range rects {
+			move(i, bounds.X, r.Y)
+		}
+	case AlignRight:
+		for i, r := range rects {
+			move(i, bounds.Right()-r.W, r.Y)
+		}
+	case AlignTop:
+		for i, r := range rects {
+			move(i, r.X, bounds.Y)
+		}
+	case AlignBottom:
+		for i, r := range rects {
+			move(i, r.X, bounds.Bottom()-r.H)
+		}
+	case AlignCenterX:
+		for i, r := range rects {
+			move(i, bounds.X+(bounds.W-r.W)/2, r.Y)
+		}
+	case AlignCenterY:
+		for i, r := range rects {
+			move(i, r.X, bounds.Y+(bounds.H-r.H)/2)
+		}
+	case DistributeX, PackX:
+		order := sortedBy(rects, func(r Rect) int { return r.X })
+		gap := 0.0
+		if op == DistributeX {
+			gap = spacing(rects, order, bounds.W, func(r Rect) int { return r.W })
+		}
+		pos := float64(bounds.X)
+		for _, i := range order {
+			move(i, int(math.Round(pos)), rects[i].Y)
+			pos += float64(rects[i].W) + gap
+		}
+	case DistributeY, PackY:
+		order := sortedBy(rects, func(r Rect) int { return r.Y })
+		gap := 0.0
+		if op == DistributeY {
+			gap = spacing(rects, order, bounds.H, func(r Rect) int { return r.H })
+		}
+		pos := float64(bounds.Y)
+		for _, i := range order {
+			move(i, rects[i].X, int(math.Round(pos)))
+			pos += float64(rects[i].H) + gap
+		}
+	}
+
+	Normalize(rules)
+}
+
+// sortedBy returns the indices of rects ordered by key
+func sortedBy(rects []Rect, key func(Rect) int) []int {
+	order := make([]int, len(rects))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return key(rects[order[a]]) < key(rects[order[b]]) })
+	return order
+}
+
+// spacing is the equal gap that fills span with the given monitors. When
+// they don't fit it is 0, so they end up packed instead of overlapping.
+func spacing(rects []Rect, order []int, span int, size func(Rect) int) float64 {
+	total := 0
+	for _, i := range order {
+		total += size(rects[i])
+	}
+	gap := float64(span-total) / float64(len(order)-1)
+	return math.Max(gap, 0)
+}
+
+// Normalize shifts the whole layout so its top-left corner is at 0,0
+func Normalize(rules map[string]MonitorRule) {
+	if len(rules) == 0 {
+		return
+	}
+	minX, minY := math.MaxInt, math.MaxInt
+	for _, r := range rules {
+		minX = min(minX, r.X)
+		minY = min(minY, r.Y)
+	}
+	if minX == 0 && minY == 0 {
+		return
+	}
+	for id, r := range rules {
+		r.X -= minX
+		r.Y -= minY
+		rules[id] = r
+	}
+}
@@ -0,0 +1,83 @@
+package config
+
+import "fmt"
+
+// Severity of a parser diagnostic
+type Severity int
+
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Diagnostic is a problem found in the config file, tied to a line
+type Diagnostic struct {
+	Line     int // 1-based
+	Severity Severity
+	RuleID   string
+	Message  string
+	Fixable  bool // Fix can resolve it without guessing values
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("line %d: %s: %s", d.Line, d.Severity, d.Message)
+}
+
+// HasErrors reports whether any diagnostic is an error
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Fix rewrites the rules that only have fixable diagnostics: duplicate lines
+// are collapsed into the winning definition and malformed pairs or unknown
+// keys are dropped. Rules with bad values are left alone since we can't know
+// what was meant. Returns how many diagnostics were resolved.
+func (p *ConfigParser) Fix() (int, error) {
+	rules, err := p.Parse()
+	if err != nil {
+		return 0, err
+	}
+
+	unfixable := make(map[string]bool)
+	for _, d := range p.Diagnostics {
+		if !d.Fixable {
+			unfixable[d.RuleID] = true
+		}
+	}
+
+	var toWrite []MonitorRule
+	written := make(map[string]bool)
+	fixed := 0
+	for _, d := range p.Diagnostics {
+		if !d.Fixable || d.RuleID == "" || unfixable[d.RuleID] {
+			continue
+		}
+		fixed++
+		if !written[d.RuleID] {
+			toWrite = append(toWrite, rules[d.RuleID])
+			written[d.RuleID] = true
+		}
+	}
+
+	if len(toWrite) == 0 {
+		return 0, nil
+	}
+	if err := p.Save(toWrite); err != nil {
+		return 0, err
+	}
+	// Refresh Lines and Diagnostics
+	_, err = p.Parse()
+	return fixed, err
+}
@@ -0,0 +1,160 @@
+package config
+
+// Rect is an area in layout (logical) coordinates
+type Rect struct {
+	X, Y, W, H int
+}
+
+func (r Rect) Right() int  { return r.X + r.W }
+func (r Rect) Bottom() int { return r.Y + r.H }
+
+// Overlaps reports whether the two rects share any area
+func (r Rect) Overlaps(o Rect) bool {
+	return r.X < o.Right() && o.X < r.Right() && r.Y < o.Bottom() && o.Y < r.Bottom()
+}
+
+// Touches reports whether the rects share an edge segment without overlapping
+func (r Rect) Touches(o Rect) bool {
+	if r.Overlaps(o) {
+		return false
+	}
+	vertical := (r.Right() == o.X || o.Right() == r.X) && r.Y < o.Bottom() && o.Y < r.Bottom()
+	horizontal := (r.Bottom() == o.Y || o.Bottom() == r.Y) && r.X < o.Right() && o.X < r.Right()
+	return vertical || horizontal
+}
+
+// LogicalSize is the size the monitor takes up in the layout, after the
+// transform and scale are applied. Like wlroots, the result is truncated.
+func (r MonitorRule) LogicalSize() (int, int) {
+	w, h := r.Width, r.Height
+	if r.Transform%2 == 1 {
+		w, h = h, w
+	}
+	if r.Scale > 0 {
+		w = int(float64(w) / r.Scale)
+		h = int(float64(h) / r.Scale)
+	}
+	return w, h
+}
+
+// Rect returns the area the monitor covers in the layout
+func (r MonitorRule) Rect() Rect {
+	w, h := r.LogicalSize()
+	return Rect{X: r.X, Y: r.Y, W: w, H: h}
+}
+
+// Relation says on which side of another monitor one is placed
+type Relation int
+
+const (
+	RightOf Relation = iota
+	LeftOf
+	Above
+	Below
+)
+
+var relationNames = []string{"right of", "left of", "above", "below"}
+
+func (r Relation) String() string {
+	if r >= 0 && int(r) < len(relationNames) {
+		return relationNames[r]
+	}
+	return "?"
+}
+
+// Alignment along the shared edge when placing relative to another monitor
+type Alignment int
+
+const (
+	AlignStart  Alignment = iota // Top or left edges line up
+	AlignCenter                  // Centers line up
+	AlignEnd                     // Bottom or right edges line up
+)
+
+// Name depends on the relation: monitors beside each other align top,
+// center or bottom, stacked ones left, center or right
+func (a Alignment) Name(r Relation) string {
+	names := []string{"top", "center", "bottom"}
+	if r == Above || r == Below {
+		names = []string{"left", "center", "right"}
+	}
+	if a >= 0 && int(a) < len(names) {
+		return names[a]
+	}
+	return "?"
+}
+
+// PlaceRelative returns the position for a monitor of size w x h placed
+// next to target
+func PlaceRelative(w, h int, target Rect, rel Relation, align Alignment) (int, int) {
+	var x, y int
+	switch rel {
+	case RightOf:
+		x = target.Right()
+	case LeftOf:
+		x = target.X - w
+	case Above:
+		y = target.Y - h
+	case Below:
+		y = target.Bottom()
+	}
+
+	if rel == RightOf || rel == LeftOf {
+		switch align {
+		case AlignStart:
+			y = target.Y
+		case AlignCenter:
+			y = target.Y + (target.H-h)/2
+		case AlignEnd:
+			y = target.Bottom() - h
+		}
+	} else {
+		switch align {
+		case AlignStart:
+			x = target.X
+		case AlignCenter:
+			x = target.X + (target.W-w)/2
+		case AlignEnd:
+			x = target.Right() - w
+		}
+	}
+	return x, y
+}
+
+// Union is the smallest rect containing both
+func (r Rect) Union(o Rect) Rect {
+	x, y := min(r.X, o.X), min(r.Y, o.Y)
+	return Rect{X: x, Y: y, W: max(r.Right(), o.Right()) - x, H: max(r.Bottom(), o.Bottom()) - y}
+}
+
+// Edge is a side of a monitor's box in the layout
+type Edge int
+
+const (
+	EdgeTop Edge = iota
+	EdgeRight
+	EdgeBottom
+	EdgeLeft
+)
+
+var edgeNames = []string{"top", "right", "bottom", "left"}
+
+func (e Edge) String() string {
+	if e >= 0 && int(e) < len(edgeNames) {
+		return edgeNames[e]
+	}
+	return "?"
+}
+
+// PanelTop returns the side of the layout box where the panel's own top
+// edge ends up under a transform. Following wl_output, rr:1 compensates for
+// a monitor turned 90° clockwise, so its top edge is on the right; the
+// flipped variants mirror the picture first.
+func PanelTop(transform int) Edge {
+	return []Edge{EdgeTop, EdgeRight, EdgeBottom, EdgeLeft, EdgeTop, EdgeLeft, EdgeBottom, EdgeRight}[transform&7]
+}
+
+// Flipped reports whether the transform mirrors the picture
+func Flipped(transform int) bool {
+	return transform&4 != 0
+}
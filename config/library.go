@@ -0,0 +1,176 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ModelRule gives default settings for every unit of a monitor model, e.g.
+//
+//	modelrule=make:DEL,model:DELL U2723QE,width:3840,height:2160,refresh:60,scale:1.5
+//
+// make is the EDID manufacturer ID, model the EDID monitor name or the
+// product code in hex. size optionally restricts the rule to a diagonal in
+// inches, for models sold in several sizes under one name.
+type ModelRule struct {
+	Line        int
+	Make, Model string
+	Size        float64 // 0 matches any size
+
+	Width, Height int
+	RefreshRate   float64
+	Scale         float64
+	Transform     int
+	VRR           int
+
+	set map[string]bool // Setting keys given on the line
+}
+
+// Library is a list of model rules in file order
+type Library []ModelRule
+
+// librarySizeTolerance is how far the EDID diagonal may be from size, EDID
+// sizes are rounded to whole centimetres or millimetres
+const librarySizeTolerance = 0.5
+
+// LoadLibrary reads a model rules file. A missing file is an empty library.
+// Lines that can't be used are reported and skipped.
+func LoadLibrary(path string) (Library, []Diagnostic, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	var lib Library
+	var diags []Diagnostic
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		trimmed := strings.TrimSpace(scanner.Text())
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		val, ok := strings.CutPrefix(trimmed, "modelrule=")
+		if !ok {
+			diags = append(diags, Diagnostic{Line: lineNo, Severity: SeverityWarning, Message: "not a modelrule line, ignored"})
+			continue
+		}
+		r, err := parseModelRule(val)
+		if err != nil {
+			diags = append(diags, Diagnostic{Line: lineNo, Severity: SeverityError, Message: err.Error()})
+			continue
+		}
+		r.Line = lineNo
+		lib = append(lib, r)
+	}
+	return lib, diags, scanner.Err()
+}
+
+func parseModelRule(val string) (ModelRule, error) {
+	r := ModelRule{set: make(map[string]bool)}
+	for _, part := range strings.Split(val, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, v, ok := strings.Cut(part, ":")
+		if !ok {
+			return r, fmt.Errorf("malformed key:value pair %q", part)
+		}
+		key, v = strings.TrimSpace(key), strings.TrimSpace(v)
+
+		var err error
+		switch key {
+		case "make":
+			r.Make = v
+		case "model":
+			r.Model = v
+		case "size":
+			r.Size, err = strconv.ParseFloat(v, 64)
+		case "width":
+			r.Width, err = strconv.Atoi(v)
+		case "height":
+			r.Height, err = strconv.Atoi(v)
+		case "refresh":
+			r.RefreshRate, err = strconv.ParseFloat(v, 64)
+		case "scale":
+			r.Scale, err = strconv.ParseFloat(v, 64)
+		case "rr":
+			r.Transform, err = strconv.Atoi(v)
+		case "vrr":
+			r.VRR, err = strconv.Atoi(v)
+		default:
+			return r, fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return r, fmt.Errorf("invalid value for %s: %q", key, v)
+		}
+		r.set[key] = true
+	}
+
+	if r.Make == "" || r.Model == "" {
+		return r, fmt.Errorf("modelrule needs make and model")
+	}
+	if r.set["width"] != r.set["height"] {
+		return r, fmt.Errorf("width and height must be given together")
+	}
+	return r, nil
+}
+
+// Lookup finds the rule for a monitor. models are the names the monitor
+// may be listed under (EDID name and product code); diagonal is in inches,
+// 0 if unknown. Rules with a matching size win over ones without, then the
+// first one in the file.
+func (l Library) Lookup(make string, models []string, diagonal float64) (ModelRule, bool) {
+	var best ModelRule
+	found := false
+	for _, r := range l {
+		if !strings.EqualFold(r.Make, make) || !matchesAny(r.Model, models) {
+			continue
+		}
+		if r.Size > 0 && (diagonal == 0 || math.Abs(r.Size-diagonal) > librarySizeTolerance) {
+			continue
+		}
+		if !found || (r.Size > 0 && best.Size == 0) {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
+
+func matchesAny(model string, models []string) bool {
+	for _, m := range models {
+		if m != "" && strings.EqualFold(model, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply sets the settings given in the model rule on a monitor rule
+func (r ModelRule) Apply(rule *MonitorRule) {
+	if r.set["width"] {
+		rule.Width, rule.Height = r.Width, r.Height
+	}
+	if r.set["refresh"] {
+		rule.RefreshRate = r.RefreshRate
+	}
+	if r.set["scale"] {
+		rule.Scale = r.Scale
+	}
+	if r.set["rr"] {
+		rule.Transform = r.Transform
+	}
+	if r.set["vrr"] {
+		rule.VariableRefreshRate = r.VRR
+	}
+}
@@ -3,7 +3,10 @@ package config
 import (
 	"bufio"
 	"fmt"
+	"math"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -16,24 +19,61 @@ type MonitorRule struct {
 	X, Y                int
 	Width, Height       int
 	RefreshRate         float64
-	VariableRefreshRate int // 0 or 1
+	VariableRefreshRate int // VRROff or VRROn
+
+	// Stored in a comment line next to the rule, see Solve
+	Anchor Anchor
 }
 
+// Values of the vrr key. MangoWC only documents on and off per monitor;
+// there is no fullscreen-only mode like Hyprland's vrr 2, so other values
+// are kept as read but reported by ValidateRule (`mangomon lint`).
+const (
+	VRROff = 0
+	VRROn  = 1
+)
+
 // ToString converts the rule back to the config string format
 func (r MonitorRule) ToString() string {
 	// monitorrule=name:eDP-1,width:1920,height:1080,refresh:60,x:0,y:0,scale:1.0,vrr:0,rr:0
-	return fmt.Sprintf("monitorrule=name:%s,width:%d,height:%d,refresh:%.0f,x:%d,y:%d,scale:%.2f,vrr:%d,rr:%d",
-		r.ID, r.Width, r.Height, r.RefreshRate, r.X, r.Y, r.Scale, r.VariableRefreshRate, r.Transform)
+	return fmt.Sprintf("monitorrule=name:%s,width:%d,height:%d,refresh:%s,x:%d,y:%d,scale:%s,vrr:%d,rr:%d",
+		r.ID, r.Width, r.Height, formatRefresh(r.RefreshRate), r.X, r.Y, FormatScale(r.Scale), r.VariableRefreshRate, r.Transform)
+}
+
+// formatRefresh keeps whole rates as before ("60") but doesn't round away
+// the fraction of rates like 59.951 that custom CVT modes produce
+func formatRefresh(rate float64) string {
+	return strconv.FormatFloat(math.Round(rate*1000)/1000, 'f', -1, 64)
+}
+
+// FormatScale keeps the usual two decimals ("1.50") but writes fractional
+// scales like 1.0667 that two decimals would round to a blurry size
+func FormatScale(scale float64) string {
+	if math.Abs(scale*100-math.Round(scale*100)) < 1e-6 {
+		return fmt.Sprintf("%.2f", scale)
+	}
+	return strconv.FormatFloat(math.Round(scale*10000)/10000, 'f', -1, 64)
 }
 
 // ConfigParser handles reading and writing the MangoWC config
 type ConfigParser struct {
 	FilePath string
 	Lines    []string // Store all lines to preserve comments/other configs
+
+	// Populated by Parse
+	Diagnostics []Diagnostic
+	RuleLines   map[string]int // Line of the definition that won for each rule ID
 }
 
+// DefaultPath replaces the config NewParser uses when given none, e.g. the
+// copy in a capture being replayed
+var DefaultPath string
+
 func NewParser(path string) (*ConfigParser, error) {
 	// Default fallback if path is empty
+	if path == "" && DefaultPath != "" {
+		path = DefaultPath
+	}
 	if path == "" {
 		home, _ := os.UserHomeDir()
 		path = home + "/.config/mango/testmonitors.conf"
@@ -42,6 +82,9 @@ func NewParser(path string) (*ConfigParser, error) {
 }
 
 func (p *ConfigParser) Parse() (map[string]MonitorRule, error) {
+	p.Diagnostics = nil
+	p.RuleLines = make(map[string]int)
+
 	file, err := os.Open(p.FilePath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -53,60 +96,157 @@ func (p *ConfigParser) Parse() (map[string]MonitorRule, error) {
 
 	var lines []string
 	rules := make(map[string]MonitorRule)
+	anchors := make(map[string]Anchor)
+	anchorLines := make(map[string]int)
 
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := scanner.Text()
 		lines = append(lines, line)
+		lineNo := len(lines)
 
 		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, anchorPrefix) {
+			id, anchor, err := parseAnchor(strings.TrimPrefix(trimmed, anchorPrefix))
+			if err != nil {
+				p.Diagnostics = append(p.Diagnostics, Diagnostic{
+					Line:     lineNo,
+					Severity: SeverityWarning,
+					Message:  fmt.Sprintf("bad anchor, ignored: %v", err),
+				})
+				continue
+			}
+			anchors[id] = anchor
+			anchorLines[id] = lineNo
+			continue
+		}
 		if strings.HasPrefix(trimmed, "monitorrule=") {
-			// Parse the rule
-			val := strings.TrimPrefix(trimmed, "monitorrule=")
-			parts := strings.Split(val, ",")
-
-			// Expected format: key:value pairs
-			// Example: name:eDP-1,width:1920,height:1080,refresh:60,x:0,y:0,scale:1.0,vrr:0,rr:0
-			rule := MonitorRule{}
-			for _, part := range parts {
-				kv := strings.Split(strings.TrimSpace(part), ":")
-				if len(kv) != 2 {
-					continue
-				}
-				key := strings.TrimSpace(kv[0])
-				val := strings.TrimSpace(kv[1])
-
-				switch key {
-				case "name":
-					rule.ID = val
-				case "width":
-					rule.Width, _ = strconv.Atoi(val)
-				case "height":
-					rule.Height, _ = strconv.Atoi(val)
-				case "refresh":
-					rule.RefreshRate, _ = strconv.ParseFloat(val, 64)
-				case "x":
-					rule.X, _ = strconv.Atoi(val)
-				case "y":
-					rule.Y, _ = strconv.Atoi(val)
-				case "scale":
-					rule.Scale, _ = strconv.ParseFloat(val, 64)
-				case "vrr":
-					rule.VariableRefreshRate, _ = strconv.Atoi(val)
-				case "rr":
-					rule.Transform, _ = strconv.Atoi(val)
-				}
+			rule, diags := parseRule(strings.TrimPrefix(trimmed, "monitorrule="), lineNo)
+			p.Diagnostics = append(p.Diagnostics, diags...)
+
+			if rule.ID == "" {
+				p.Diagnostics = append(p.Diagnostics, Diagnostic{
+					Line:     lineNo,
+					Severity: SeverityError,
+					Message:  "monitorrule without a name, line is ignored",
+				})
+				continue
 			}
 
-			if rule.ID != "" {
-				rules[rule.ID] = rule
+			// The last definition wins, same as before, but we now say so
+			if prev, ok := p.RuleLines[rule.ID]; ok {
+				p.Diagnostics = append(p.Diagnostics, Diagnostic{
+					Line:     lineNo,
+					Severity: SeverityWarning,
+					RuleID:   rule.ID,
+					Message:  fmt.Sprintf("duplicate monitorrule for %s (also on line %d), this one wins", rule.ID, prev),
+					Fixable:  true,
+				})
 			}
+			rules[rule.ID] = rule
+			p.RuleLines[rule.ID] = lineNo
 		}
 	}
+
+	// Anchor lines may come before or after their rule
+	for id, anchor := range anchors {
+		rule, ok := rules[id]
+		if !ok {
+			p.Diagnostics = append(p.Diagnostics, Diagnostic{
+				Line:     anchorLines[id],
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("anchor for %s, which has no monitorrule", id),
+			})
+			continue
+		}
+		rule.Anchor = anchor
+		rules[id] = rule
+	}
+	sort.SliceStable(p.Diagnostics, func(i, j int) bool { return p.Diagnostics[i].Line < p.Diagnostics[j].Line })
+
 	p.Lines = lines
 	return rules, scanner.Err()
 }
 
+// parseRule parses the value of a monitorrule line and reports anything it
+// had to skip over.
+func parseRule(val string, lineNo int) (MonitorRule, []Diagnostic) {
+	// Expected format: key:value pairs
+	// Example: name:eDP-1,width:1920,height:1080,refresh:60,x:0,y:0,scale:1.0,vrr:0,rr:0
+	rule := MonitorRule{}
+	var diags []Diagnostic
+
+	// The name is needed to attribute diagnostics, so find it first
+	for _, part := range strings.Split(val, ",") {
+		kv := strings.Split(strings.TrimSpace(part), ":")
+		if len(kv) == 2 && strings.TrimSpace(kv[0]) == "name" {
+			rule.ID = strings.TrimSpace(kv[1])
+		}
+	}
+
+	report := func(sev Severity, fixable bool, format string, args ...any) {
+		diags = append(diags, Diagnostic{
+			Line:     lineNo,
+			Severity: sev,
+			RuleID:   rule.ID,
+			Message:  fmt.Sprintf(format, args...),
+			Fixable:  fixable,
+		})
+	}
+	atoi := func(key, val string) int {
+		n, err := strconv.Atoi(val)
+		if err != nil {
+			report(SeverityError, false, "invalid integer for %s: %q", key, val)
+		}
+		return n
+	}
+	parseFloat := func(key, val string) float64 {
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			report(SeverityError, false, "invalid number for %s: %q", key, val)
+		}
+		return f
+	}
+
+	for _, part := range strings.Split(val, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.Split(part, ":")
+		if len(kv) != 2 {
+			report(SeverityWarning, true, "malformed key:value pair %q", part)
+			continue
+		}
+		key := strings.TrimSpace(kv[0])
+		val := strings.TrimSpace(kv[1])
+
+		switch key {
+		case "name":
+			// Already handled above
+		case "width":
+			rule.Width = atoi(key, val)
+		case "height":
+			rule.Height = atoi(key, val)
+		case "refresh":
+			rule.RefreshRate = parseFloat(key, val)
+		case "x":
+			rule.X = atoi(key, val)
+		case "y":
+			rule.Y = atoi(key, val)
+		case "scale":
+			rule.Scale = parseFloat(key, val)
+		case "vrr":
+			rule.VariableRefreshRate = atoi(key, val)
+		case "rr":
+			rule.Transform = atoi(key, val)
+		default:
+			report(SeverityWarning, true, "unknown key %q", key)
+		}
+	}
+	return rule, diags
+}
+
 func (p *ConfigParser) Save(newRules []MonitorRule) error {
 	// Reconstruct the file content
 	// This is a naive implementation: it replaces existing monitorrule lines
@@ -122,9 +262,32 @@ func (p *ConfigParser) Save(newRules []MonitorRule) error {
 	updatedLines := make([]string, 0, len(p.Lines))
 	writtenIDs := make(map[string]bool)
 
+	// Anchor lines are rewritten right before their rule, so collect the
+	// existing ones for the rules we keep as they are
+	oldAnchors := make(map[string]string)
 	for _, line := range p.Lines {
 		trimmed := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmed, "monitorrule=") {
+		if strings.HasPrefix(trimmed, anchorPrefix) {
+			if id, _, err := parseAnchor(strings.TrimPrefix(trimmed, anchorPrefix)); err == nil {
+				oldAnchors[id] = line
+			}
+		}
+	}
+	writeRule := func(r MonitorRule) {
+		if r.Anchor.IsSet() {
+			updatedLines = append(updatedLines, anchorLine(r))
+		}
+		updatedLines = append(updatedLines, r.ToString())
+	}
+
+	for _, line := range p.Lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, anchorPrefix) {
+			if _, _, err := parseAnchor(strings.TrimPrefix(trimmed, anchorPrefix)); err == nil {
+				continue
+			}
+			updatedLines = append(updatedLines, line) // Leave broken ones for check to report
+		} else if strings.HasPrefix(trimmed, "monitorrule=") {
 			// Check if this line corresponds to one of our new rules
 			// Extract the monitor ID from the line
 			// Format: monitorrule=name:eDP-1,width:...
@@ -137,10 +300,15 @@ func (p *ConfigParser) Save(newRules []MonitorRule) error {
 				}
 			}
 
+			// A rule we already wrote is a duplicate line, drop it
+			if writtenIDs[currentID] {
+				continue
+			}
+
 			foundRequest := false
 			for _, nr := range newRules {
 				if nr.ID == currentID {
-					updatedLines = append(updatedLines, nr.ToString())
+					writeRule(nr)
 					writtenIDs[nr.ID] = true
 					foundRequest = true
 					break
@@ -151,6 +319,10 @@ func (p *ConfigParser) Save(newRules []MonitorRule) error {
 				// Or provided list is authoritative?
 				// Usually for TUI, what isn't configured might be kept.
 				// Let's keep it unless we want to delete.
+				if anchor, ok := oldAnchors[currentID]; ok {
+					updatedLines = append(updatedLines, anchor)
+					delete(oldAnchors, currentID)
+				}
 				updatedLines = append(updatedLines, line)
 			}
 		} else {
@@ -161,7 +333,7 @@ func (p *ConfigParser) Save(newRules []MonitorRule) error {
 	// Append new rules that weren't in the file
 	for _, nr := range newRules {
 		if !writtenIDs[nr.ID] {
-			updatedLines = append(updatedLines, nr.ToString())
+			writeRule(nr)
 		}
 	}
 
@@ -181,8 +353,13 @@ func (p *ConfigParser) Save(newRules []MonitorRule) error {
 
 // Profile management
 
+// ProfilesDir is the "profiles" directory next to the config file
+func (p *ConfigParser) ProfilesDir() string {
+	return filepath.Join(filepath.Dir(p.FilePath), "profiles")
+}
+
 func (p *ConfigParser) ListProfiles() ([]string, error) {
-	profilesDir := strings.ReplaceAll(p.FilePath, "config.conf", "profiles")
+	profilesDir := p.ProfilesDir()
 	entries, err := os.ReadDir(profilesDir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -200,8 +377,36 @@ func (p *ConfigParser) ListProfiles() ([]string, error) {
 	return profiles, nil
 }
 
+// CheckProfileName rejects names that can't be used as a file in the
+// profiles directory, e.g. ones that would write outside it
+func CheckProfileName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\\\x00") {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+	return nil
+}
+
+// SanitizeProfileName turns a name from another tool into one
+// CheckProfileName accepts
+func SanitizeProfileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == 0 {
+			return '-'
+		}
+		return r
+	}, name)
+	name = strings.TrimLeft(name, ".-")
+	if name == "" {
+		return "imported"
+	}
+	return name
+}
+
 func (p *ConfigParser) SaveProfile(name string, rules []MonitorRule) error {
-	profilesDir := strings.ReplaceAll(p.FilePath, "config.conf", "profiles")
+	if err := CheckProfileName(name); err != nil {
+		return err
+	}
+	profilesDir := p.ProfilesDir()
 	if err := os.MkdirAll(profilesDir, 0755); err != nil {
 		return err
 	}
@@ -220,13 +425,19 @@ func (p *ConfigParser) SaveProfile(name string, rules []MonitorRule) error {
 
 	w := bufio.NewWriter(file)
 	for _, r := range rules {
+		if r.Anchor.IsSet() {
+			fmt.Fprintln(w, anchorLine(r))
+		}
 		fmt.Fprintln(w, r.ToString())
 	}
 	return w.Flush()
 }
 
 func (p *ConfigParser) LoadProfile(name string) (map[string]MonitorRule, error) {
-	profilesDir := strings.ReplaceAll(p.FilePath, "config.conf", "profiles")
+	if err := CheckProfileName(name); err != nil {
+		return nil, err
+	}
+	profilesDir := p.ProfilesDir()
 	path := fmt.Sprintf("%s/%s.conf", profilesDir, name)
 
 	// Create a temporary parser for the profile file
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Presentation is one of the classic projector modes
+type Presentation int
+
+const (
+	PresentExtend   Presentation = iota // All monitors side by side
+	PresentMirror                       // Everything shows the internal panel
+	PresentExternal                     // Internal panel off
+	PresentInternal                     // External monitors off
+)
+
+// Presentations lists every mode in menu order
+var Presentations = []Presentation{PresentExtend, PresentMirror, PresentExternal, PresentInternal}
+
+var presentationNames = []string{"extend", "mirror", "external", "internal"}
+
+func (p Presentation) String() string {
+	if p >= 0 && int(p) < len(presentationNames) {
+		return presentationNames[p]
+	}
+	return "?"
+}
+
+// ParsePresentation accepts the names used by `mangomon switch`
+func ParsePresentation(name string) (Presentation, error) {
+	for i, n := range presentationNames {
+		if n == name {
+			return Presentation(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown mode %q, want one of %s", name, strings.Join(presentationNames, ", "))
+}
+
+// internalPrefixes are connector types used for built-in panels
+var internalPrefixes = []string{"eDP", "LVDS", "DSI"}
+
+// IsInternal reports whether the connector is a built-in panel
+func IsInternal(id string) bool {
+	for _, p := range internalPrefixes {
+		if strings.HasPrefix(id, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Present rearranges the rules of the given (connected) monitors for a
+// presentation mode and returns the ones that should be turned off.
+// Extend packs the monitors side by side, internal panel first, keeping
+// the left-to-right order of the others; mirror gives every monitor the
+// internal panel's position and mode like the mirror picker does.
+func Present(rules map[string]MonitorRule, ids []string, mode Presentation) ([]string, error) {
+	var internal, external []string
+	for _, id := range ids {
+		if _, ok := rules[id]; !ok {
+			continue
+		}
+		if IsInternal(id) {
+			internal = append(internal, id)
+		} else {
+			external = append(external, id)
+		}
+	}
+	byX := func(list []string) {
+		sort.SliceStable(list, func(i, j int) bool { return rules[list[i]].X < rules[list[j]].X })
+	}
+	byX(internal)
+	byX(external)
+
+	switch mode {
+	case PresentExtend:
+		order := append(append([]string{}, internal...), external...)
+		// Mirrored monitors share an X, so start from a clean row in the
+		// wanted order before packing
+		for i, id := range order {
+			r := rules[id]
+			r.X, r.Y = i, 0
+			r.Anchor = Anchor{}
+			rules[id] = r
+		}
+		Arrange(rules, order, PackX)
+		return nil, nil
+
+	case PresentMirror:
+		all := append(append([]string{}, internal...), external...)
+		if len(all) < 2 {
+			return nil, errors.New("mirroring needs at least two monitors")
+		}
+		ref := rules[all[0]]
+		for _, id := range all[1:] {
+			r := rules[id]
+			r.X, r.Y = ref.X, ref.Y
+			r.Width, r.Height = ref.Width, ref.Height
+			r.Scale, r.Transform = ref.Scale, ref.Transform
+			r.Anchor = Anchor{}
+			rules[id] = r
+		}
+		return nil, nil
+
+	case PresentExternal:
+		if len(external) == 0 {
+			return nil, errors.New("no external monitor connected")
+		}
+		return internal, nil
+
+	case PresentInternal:
+		if len(internal) == 0 {
+			return nil, errors.New("no internal panel found")
+		}
+		return external, nil
+	}
+	return nil, fmt.Errorf("unknown presentation mode %d", mode)
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"math"
+	"sort"
+)
+
+// ScaleDenominator is the fractional-scale-v1 granularity: compositors
+// round scales to multiples of 1/120
+const ScaleDenominator = 120
+
+// snapTolerance is how far SnapScale may move away from the ideal scale
+// to reach one that gives a whole logical size
+const snapTolerance = 0.03
+
+// ExactScale reports whether the scale gives a whole logical size for a
+// mode, so windows don't get blurry edges
+func ExactScale(width, height int, scale float64) bool {
+	k := int(math.Round(scale * ScaleDenominator))
+	return k > 0 && (width*ScaleDenominator)%k == 0 && (height*ScaleDenominator)%k == 0
+}
+
+// SnapScale rounds ideal to 1/120 and, if a scale within 3% gives a whole
+// logical size for the mode, returns the closest such scale instead
+func SnapScale(width, height int, ideal float64) float64 {
+	k := int(math.Round(ideal * ScaleDenominator))
+	if k < 1 {
+		k = 1
+	}
+	limit := int(math.Ceil(ideal * snapTolerance * ScaleDenominator))
+	for d := 0; d <= limit; d++ {
+		for _, c := range []int{k - d, k + d} {
+			if c > 0 && ExactScale(width, height, float64(c)/ScaleDenominator) {
+				return float64(c) / ScaleDenominator
+			}
+		}
+	}
+	return float64(k) / ScaleDenominator
+}
+
+// MatchScales sets the scale of every monitor with a known density so text
+// has the same physical size as on the reference monitor. ppi maps monitor
+// IDs to their physical pixel density; monitors missing from it are left
+// alone and returned.
+func MatchScales(rules map[string]MonitorRule, ref string, ppi map[string]float64) (skipped []string) {
+	refRule, ok := rules[ref]
+	if !ok || ppi[ref] <= 0 || refRule.Scale <= 0 {
+		for id := range rules {
+			if id != ref {
+				skipped = append(skipped, id)
+			}
+		}
+		sort.Strings(skipped)
+		return skipped
+	}
+
+	// Pixels per physical inch the user sees on the reference monitor
+	target := ppi[ref] / refRule.Scale
+	for id, r := range rules {
+		if id == ref {
+			continue
+		}
+		if ppi[id] <= 0 {
+			skipped = append(skipped, id)
+			continue
+		}
+		r.Scale = SnapScale(r.Width, r.Height, ppi[id]/target)
+		rules[id] = r
+	}
+	sort.Strings(skipped)
+	return skipped
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidateRule checks the values of a single rule. The line is used for the
+// returned diagnostics.
+func ValidateRule(r MonitorRule, line int) []Diagnostic {
+	var diags []Diagnostic
+	report := func(sev Severity, format string, args ...any) {
+		diags = append(diags, Diagnostic{
+			Line:     line,
+			Severity: sev,
+			RuleID:   r.ID,
+			Message:  fmt.Sprintf("%s: ", r.ID) + fmt.Sprintf(format, args...),
+		})
+	}
+
+	if r.Width <= 0 || r.Height <= 0 {
+		report(SeverityError, "width and height must be positive, got %dx%d", r.Width, r.Height)
+	}
+	if r.RefreshRate <= 0 {
+		report(SeverityError, "refresh must be positive, got %g", r.RefreshRate)
+	} else if r.RefreshRate < 23 || r.RefreshRate > 500 {
+		report(SeverityWarning, "refresh %gHz is unusual for a display", r.RefreshRate)
+	}
+	if r.Scale <= 0 {
+		report(SeverityError, "scale must be greater than 0, got %g", r.Scale)
+	} else if r.Scale > 10 {
+		report(SeverityWarning, "scale %g is unusually large", r.Scale)
+	}
+	if r.Transform < 0 || r.Transform > 7 {
+		report(SeverityError, "rr (transform) must be between 0 and 7, got %d", r.Transform)
+	}
+	if r.VariableRefreshRate != VRROff && r.VariableRefreshRate != VRROn {
+		report(SeverityError, "vrr must be 0 or 1, got %d", r.VariableRefreshRate)
+	}
+	return diags
+}
+
+// ValidateLayout checks the arrangement of the rules in logical coordinates:
+// monitors must not overlap (identical areas are mirrors and are fine) and
+// every monitor should be connected to the others through monitors that
+// touch, with a warning for each group separated by a gap. Anchors must
+// point at an existing monitor without looping back. lines maps rule IDs to
+// their line in the config, as recorded by Parse.
+func ValidateLayout(rules map[string]MonitorRule, lines map[string]int) []Diagnostic {
+	var ids []string
+	for id, r := range rules {
+		// Rules with broken sizes are already reported by ValidateRule
+		if w, h := r.LogicalSize(); w > 0 && h > 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return lines[ids[i]] < lines[ids[j]] })
+
+	var diags []Diagnostic
+
+	solved := make(map[string]MonitorRule, len(rules))
+	for id, r := range rules {
+		solved[id] = r
+	}
+	for _, id := range Solve(solved) {
+		diags = append(diags, Diagnostic{
+			Line:     lines[id],
+			Severity: SeverityWarning,
+			RuleID:   id,
+			Message:  fmt.Sprintf("%s is anchored to %s, which is missing or anchored back to it", id, rules[id].Anchor.Target),
+		})
+	}
+
+	for i, a := range ids {
+		ra := rules[a].Rect()
+		for _, b := range ids[i+1:] {
+			rb := rules[b].Rect()
+			if ra != rb && ra.Overlaps(rb) {
+				diags = append(diags, Diagnostic{
+					Line:     lines[b],
+					Severity: SeverityError,
+					RuleID:   b,
+					Message:  fmt.Sprintf("%s overlaps %s (line %d)", b, a, lines[a]),
+				})
+			}
+		}
+	}
+
+	if len(ids) < 2 {
+		return diags
+	}
+	// Monitors that touch form groups; more than one group means a gap
+	touching := func(a, b string) bool {
+		ra, rb := rules[a].Rect(), rules[b].Rect()
+		return ra == rb || ra.Touches(rb) || ra.Overlaps(rb)
+	}
+	group := make(map[string]int)
+	var groups [][]string
+	for _, start := range ids {
+		if _, seen := group[start]; seen {
+			continue
+		}
+		n := len(groups)
+		group[start] = n
+		members := []string{start}
+		for i := 0; i < len(members); i++ {
+			for _, b := range ids {
+				if _, seen := group[b]; !seen && touching(members[i], b) {
+					group[b] = n
+					members = append(members, b)
+				}
+			}
+		}
+		groups = append(groups, members)
+	}
+	if len(groups) < 2 {
+		return diags
+	}
+	// The group of the first rule in the file is the main one
+	for _, g := range groups[1:] {
+		diags = append(diags, Diagnostic{
+			Line:     lines[g[0]],
+			Severity: SeverityWarning,
+			RuleID:   g[0],
+			Message:  fmt.Sprintf("%s not connected to %s (gap in layout)", describeGroup(g), strings.Join(groups[0], ", ")),
+		})
+	}
+	return diags
+}
+
+// describeGroup names the monitors of a separated group for a diagnostic
+func describeGroup(ids []string) string {
+	if len(ids) == 1 {
+		return ids[0] + " is"
+	}
+	return strings.Join(ids, ", ") + " are"
+}
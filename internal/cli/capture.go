@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mangomon/config"
+	"mangomon/internal/state"
+	"mangomon/internal/system"
+)
+
+// Where the files mangomon itself reads go in a capture, next to what
+// system.Capture writes
+const (
+	captureConfig = "config/config.conf" // Profiles go in config/profiles
+	captureAppDir = "mangomon"           // Model library and state
+)
+
+// Capture implements `mangomon capture [--config FILE] <dir|file.tar.gz>`.
+// It snapshots the outputs, sysfs files, config and profiles so the setup
+// can be replayed with --replay.
+func Capture(args []string) int {
+	flags := flag.NewFlagSet("capture", flag.ContinueOnError)
+	configPath := flags.String("config", "", "MangoWC config to include")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: mangomon capture [flags] <dir|file.tar.gz>\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	target := flags.Arg(0)
+	tarball := strings.HasSuffix(target, ".tar.gz") || strings.HasSuffix(target, ".tgz")
+
+	parser, err := config.NewParser(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing parser: %v\n", err)
+		return 2
+	}
+
+	dir := target
+	if tarball {
+		if dir, err = os.MkdirTemp("", "mangomon-capture-"); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		defer os.RemoveAll(dir)
+	} else if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: %s is not empty\n", dir)
+		return 2
+	} else if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	n, err := system.Capture(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error capturing outputs: %v\n", err)
+		return 1
+	}
+
+	// The config side: missing files are fine, they are missing on replay too
+	files := map[string]string{
+		parser.FilePath:        captureConfig,
+		state.GetLibraryPath(): filepath.Join(captureAppDir, "library.conf"),
+		state.GetStatePath():   filepath.Join(captureAppDir, "state.json"),
+	}
+	if profiles, err := parser.ListProfiles(); err == nil {
+		for _, p := range profiles {
+			files[filepath.Join(parser.ProfilesDir(), p+".conf")] = filepath.Join(filepath.Dir(captureConfig), "profiles", p+".conf")
+		}
+	}
+	for src, rel := range files {
+		if err := copyFile(src, filepath.Join(dir, rel)); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Error copying %s: %v\n", src, err)
+			return 1
+		}
+	}
+
+	if tarball {
+		if err := writeTarball(target, dir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", target, err)
+			return 1
+		}
+	}
+	fmt.Printf("Captured %d connector(s) and %s to %s\n", n, parser.FilePath, target)
+	fmt.Println("It contains the monitors' EDIDs, including serial numbers.")
+	return 0
+}
+
+// StartReplay points mangomon at a capture: hardware, config, profiles and
+// its own files, so nothing outside the capture is read or changed
+func StartReplay(dir string) error {
+	if err := system.Replay(dir); err != nil {
+		return err
+	}
+	config.DefaultPath = filepath.Join(dir, captureConfig)
+	state.SetDir(filepath.Join(dir, captureAppDir))
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0644)
+}
+
+// writeTarball packs the contents of dir into a gzipped tar at path, with
+// paths relative to dir
+func writeTarball(path, dir string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == dir {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"mangomon/config"
+)
+
+// Check implements `mangomon check [--fix] [file]`. It prints the parser
+// diagnostics and optionally fixes the ones that can be fixed safely.
+func Check(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	fix := fs.Bool("fix", false, "fix duplicate and malformed rules in place")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	parser, err := config.NewParser(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing parser: %v\n", err)
+		return 2
+	}
+
+	if *fix {
+		n, err := parser.Fix()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fixing %s: %v\n", parser.FilePath, err)
+			return 2
+		}
+		if n > 0 {
+			fmt.Printf("Fixed %d problem(s) in %s\n", n, parser.FilePath)
+		}
+	} else if _, err := parser.Parse(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", parser.FilePath, err)
+		return 2
+	}
+
+	printDiagnostics(os.Stdout, parser.FilePath, parser.Diagnostics)
+	if len(parser.Diagnostics) == 0 {
+		fmt.Printf("No problems found in %s\n", parser.FilePath)
+		return 0
+	}
+	if !*fix {
+		for _, d := range parser.Diagnostics {
+			if d.Fixable {
+				fmt.Println("\nRun `mangomon check --fix` to fix the problems marked (fixable).")
+				break
+			}
+		}
+	}
+	if config.HasErrors(parser.Diagnostics) {
+		return 1
+	}
+	return 0
+}
+
+func printDiagnostics(w io.Writer, path string, diags []config.Diagnostic) {
+	for _, d := range diags {
+		suffix := ""
+		if d.Fixable {
+			suffix = " (fixable)"
+		}
+		fmt.Fprintf(w, "%s:%d: %s: %s%s\n", path, d.Line, d.Severity, d.Message, suffix)
+	}
+}
@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"mangomon/config"
+	"mangomon/internal/state"
+	"mangomon/internal/system"
+)
+
+// Result of a doctor check
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// doctorTimeout bounds the mmsg query, a hung compositor is a finding too
+const doctorTimeout = 3 * time.Second
+
+// Doctor implements `mangomon doctor [--json] [--config FILE]`. It checks
+// the environment mangomon depends on and exits 1 if any check failed.
+func Doctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	configPath := fs.String("config", "", "MangoWC config to check")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	parser, err := config.NewParser(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing parser: %v\n", err)
+		return 2
+	}
+
+	var checks []doctorCheck
+	add := func(name, status, format string, args ...any) {
+		checks = append(checks, doctorCheck{Name: name, Status: status, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	// Compositor, or the capture standing in for it
+	replaying := system.ReplayDir() != ""
+	switch display := os.Getenv("WAYLAND_DISPLAY"); {
+	case replaying:
+		add("Wayland session", doctorPass, "replaying %s", system.ReplayDir())
+	case display != "":
+		add("Wayland session", doctorPass, "WAYLAND_DISPLAY=%s", display)
+	default:
+		add("Wayland session", doctorFail, "WAYLAND_DISPLAY is not set, not running inside MangoWC?")
+	}
+	outputs, outputsErr := []system.Output(nil), error(nil)
+	if path, err := exec.LookPath("mmsg"); err != nil && !replaying {
+		add("mmsg", doctorFail, "not found in PATH, it comes with MangoWC")
+	} else {
+		if !replaying {
+			add("mmsg", doctorPass, "%s", path)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+		outputs, outputsErr = system.GetOutputsContext(ctx)
+		cancel()
+		switch {
+		case errors.Is(outputsErr, context.DeadlineExceeded):
+			add("MangoWC", doctorFail, "mmsg -O didn't answer within %s", doctorTimeout)
+		case outputsErr != nil:
+			add("MangoWC", doctorFail, "%v", outputsErr)
+		default:
+			add("MangoWC", doctorPass, "%d output(s): %s", len(outputs), outputNames(outputs))
+		}
+	}
+
+	// Kernel
+	connected, err := system.ConnectedOutputs()
+	if err != nil {
+		add("DRM sysfs", doctorFail, "%v", err)
+	} else if len(connected) == 0 {
+		add("DRM sysfs", doctorWarn, "/sys/class/drm lists no connected outputs")
+	} else {
+		add("DRM sysfs", doctorPass, "connected: %s", outputNames(connected))
+		var missing []string
+		for _, o := range connected {
+			if _, err := system.GetEDID(o.Name); err != nil {
+				missing = append(missing, fmt.Sprintf("%s (%v)", o.Name, err))
+			}
+		}
+		if len(missing) > 0 {
+			add("EDID", doctorWarn, "unreadable for %s; physical size, model defaults and memory won't work", strings.Join(missing, ", "))
+		} else {
+			add("EDID", doctorPass, "readable for every connected output")
+		}
+	}
+	// Without MangoWC, compare the rules against what the kernel sees
+	if outputsErr != nil || outputs == nil {
+		outputs = connected
+	}
+
+	// Files
+	checks = append(checks, configFileCheck(parser.FilePath))
+	if profiles, err := parser.ListProfiles(); err != nil {
+		add("Profiles", doctorWarn, "%s: %v", parser.ProfilesDir(), err)
+	} else if len(profiles) == 0 {
+		add("Profiles", doctorPass, "none in %s", parser.ProfilesDir())
+	} else {
+		add("Profiles", doctorPass, "%d in %s: %s", len(profiles), parser.ProfilesDir(), strings.Join(profiles, ", "))
+	}
+	if _, diags, err := config.LoadLibrary(state.GetLibraryPath()); err != nil {
+		add("Model library", doctorWarn, "%v", err)
+	} else if len(diags) > 0 {
+		add("Model library", doctorWarn, "%s: %d problem(s), first: %s", state.GetLibraryPath(), len(diags), diags[0])
+	} else {
+		add("Model library", doctorPass, "%s", state.GetLibraryPath())
+	}
+
+	// Rules
+	rules, err := parser.Parse()
+	if err != nil {
+		add("Rules", doctorFail, "%v", err)
+	} else {
+		checks = append(checks, rulesCheck(rules, outputs))
+
+		diags := parser.Diagnostics
+		for id, r := range rules {
+			diags = append(diags, config.ValidateRule(r, parser.RuleLines[id])...)
+		}
+		diags = append(diags, config.ValidateLayout(rules, parser.RuleLines)...)
+		switch {
+		case config.HasErrors(diags):
+			add("Layout", doctorFail, "%d problem(s), run `mangomon lint` for details", len(diags))
+		case len(diags) > 0:
+			add("Layout", doctorWarn, "%d warning(s), run `mangomon lint` for details", len(diags))
+		default:
+			add("Layout", doctorPass, "%d rule(s), no problems", len(rules))
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(struct {
+			Checks []doctorCheck `json:"checks"`
+		}{checks}); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+	} else {
+		printDoctor(os.Stdout, checks)
+	}
+
+	for _, c := range checks {
+		if c.Status == doctorFail {
+			return 1
+		}
+	}
+	return 0
+}
+
+func printDoctor(w io.Writer, checks []doctorCheck) {
+	width := 0
+	for _, c := range checks {
+		width = max(width, len(c.Name))
+	}
+	counts := make(map[string]int)
+	for _, c := range checks {
+		fmt.Fprintf(w, "[%s] %-*s  %s\n", c.Status, width, c.Name, c.Detail)
+		counts[c.Status]++
+	}
+	fmt.Fprintf(w, "\n%d passed, %d warning(s), %d failed\n", counts[doctorPass], counts[doctorWarn], counts[doctorFail])
+}
+
+func outputNames(outputs []system.Output) string {
+	var names []string
+	for _, o := range outputs {
+		names = append(names, o.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// configFileCheck checks that the config can be written without changing
+// it. A missing file is fine if its directory is writable, Save creates it.
+func configFileCheck(path string) doctorCheck {
+	c := doctorCheck{Name: "Config file", Status: doctorPass, Detail: path}
+	if _, err := os.Stat(path); err == nil {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			c.Status, c.Detail = doctorFail, fmt.Sprintf("%s is not writable: %v", path, err)
+			return c
+		}
+		f.Close()
+		return c
+	} else if !os.IsNotExist(err) {
+		c.Status, c.Detail = doctorFail, err.Error()
+		return c
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".mangomon-doctor-*")
+	if err != nil {
+		c.Status, c.Detail = doctorFail, fmt.Sprintf("%s doesn't exist and can't be created: %v", path, err)
+		return c
+	}
+	f.Close()
+	os.Remove(f.Name())
+	c.Status, c.Detail = doctorWarn, fmt.Sprintf("%s doesn't exist yet, saving will create it", path)
+	return c
+}
+
+// rulesCheck compares the rules with the connected outputs
+func rulesCheck(rules map[string]config.MonitorRule, outputs []system.Output) doctorCheck {
+	c := doctorCheck{Name: "Rules", Status: doctorWarn}
+	if len(outputs) == 0 {
+		c.Detail = fmt.Sprintf("%d rule(s), no connected outputs to compare with", len(rules))
+		return c
+	}
+	connected := make(map[string]bool)
+	var unruled []string
+	for _, o := range outputs {
+		connected[o.Name] = true
+		if _, ok := rules[o.Name]; !ok {
+			unruled = append(unruled, o.Name)
+		}
+	}
+	var stale []string
+	for id := range rules {
+		if !connected[id] {
+			stale = append(stale, id)
+		}
+	}
+	sort.Strings(stale)
+
+	var problems []string
+	if len(unruled) > 0 {
+		problems = append(problems, "no rule for "+strings.Join(unruled, ", "))
+	}
+	if len(stale) > 0 {
+		problems = append(problems, "rules for outputs not connected: "+strings.Join(stale, ", "))
+	}
+	if len(problems) > 0 {
+		c.Detail = strings.Join(problems, "; ")
+		return c
+	}
+	c.Status = doctorPass
+	c.Detail = fmt.Sprintf("every connected output has a rule (%d)", len(rules))
+	return c
+}
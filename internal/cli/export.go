@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"mangomon/config"
+	"mangomon/internal/convert"
+)
+
+// Export implements `mangomon export [flags] <format> [file]`. Without a
+// file the result goes to stdout.
+func Export(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	profile := fs.String("profile", "", "export this mangomon profile instead of the config")
+	name := fs.String("name", "", "kanshi profile name (default: the profile name or \"mangomon\")")
+	configPath := fs.String("config", "", "MangoWC config to read")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: mangomon export [flags] <%s> [file]\n", strings.Join(convert.ExportFormats, "|"))
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fs.Usage()
+		return 2
+	}
+
+	parser, err := config.NewParser(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing parser: %v\n", err)
+		return 2
+	}
+
+	var rules map[string]config.MonitorRule
+	if *profile != "" {
+		rules, err = parser.LoadProfile(*profile)
+		if *name == "" {
+			*name = *profile
+		}
+	} else {
+		rules, err = parser.Parse()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading rules: %v\n", err)
+		return 2
+	}
+
+	var list []config.MonitorRule
+	for _, r := range rules {
+		list = append(list, r)
+	}
+
+	out := os.Stdout
+	if fs.NArg() == 2 {
+		f, err := os.Create(fs.Arg(1))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", fs.Arg(1), err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if err := convert.Export(fs.Arg(0), out, *name, list); err != nil {
+		fmt.Fprintf(os.Stderr, "Error exporting: %v\n", err)
+		return 1
+	}
+	return 0
+}
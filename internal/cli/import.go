@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"mangomon/config"
+	"mangomon/internal/convert"
+)
+
+// Import implements `mangomon import [--profile NAME] [--dry-run] <format> <file>`.
+// Single layouts are merged into the config (or saved as a profile with
+// --profile), kanshi profiles are saved as mangomon profiles.
+func Import(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	profile := fs.String("profile", "", "save as this profile instead of writing the config")
+	dryRun := fs.Bool("dry-run", false, "print the rules instead of saving them")
+	configPath := fs.String("config", "", "MangoWC config to write to")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: mangomon import [flags] <%s> <file|->\n", strings.Join(convert.ImportFormats, "|"))
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	in := os.Stdin
+	if path := fs.Arg(1); path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening %s: %v\n", path, err)
+			return 2
+		}
+		defer f.Close()
+		in = f
+	}
+
+	layouts, warnings, err := convert.Import(fs.Arg(0), in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error importing: %v\n", err)
+		return 2
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+
+	parser, err := config.NewParser(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing parser: %v\n", err)
+		return 2
+	}
+
+	for _, l := range layouts {
+		name := l.Name
+		if *profile != "" {
+			name = *profile
+			if len(layouts) > 1 {
+				name = *profile + "-" + l.Name
+			}
+		}
+		// Names come from the imported file and end up in a path
+		if name != "" && config.CheckProfileName(name) != nil {
+			safe := config.SanitizeProfileName(name)
+			fmt.Fprintf(os.Stderr, "warning: profile name %q can't be used as a file name, saving it as %q\n", name, safe)
+			name = safe
+		}
+
+		if *dryRun {
+			if name != "" {
+				fmt.Printf("# profile %s\n", name)
+			}
+			for _, r := range l.Rules {
+				fmt.Println(r.ToString())
+			}
+			continue
+		}
+
+		if name != "" {
+			if err := parser.SaveProfile(name, l.Rules); err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving profile %s: %v\n", name, err)
+				return 1
+			}
+			fmt.Printf("Saved %d rule(s) to profile %s\n", len(l.Rules), name)
+			continue
+		}
+
+		// Merge into the config, keeping everything else in the file
+		if _, err := parser.Parse(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", parser.FilePath, err)
+			return 1
+		}
+		if err := parser.Save(l.Rules); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving %s: %v\n", parser.FilePath, err)
+			return 1
+		}
+		fmt.Printf("Wrote %d rule(s) to %s\n", len(l.Rules), parser.FilePath)
+	}
+	return 0
+}
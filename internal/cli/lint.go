@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"mangomon/config"
+)
+
+// Lint implements `mangomon lint [--strict] [file]`. It needs no running
+// compositor so it can be used as a pre-commit hook. Exit codes:
+// 0 no errors, 1 errors found (or warnings with --strict), 2 unreadable file.
+func Lint(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	strict := fs.Bool("strict", false, "treat warnings as errors")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	parser, err := config.NewParser(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing parser: %v\n", err)
+		return 2
+	}
+	// Parse treats a missing file as empty, which is not what we want here
+	if _, err := os.Stat(parser.FilePath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", parser.FilePath, err)
+		return 2
+	}
+
+	rules, err := parser.Parse()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", parser.FilePath, err)
+		return 2
+	}
+
+	diags := parser.Diagnostics
+	for id, r := range rules {
+		diags = append(diags, config.ValidateRule(r, parser.RuleLines[id])...)
+	}
+	diags = append(diags, config.ValidateLayout(rules, parser.RuleLines)...)
+	sort.SliceStable(diags, func(i, j int) bool { return diags[i].Line < diags[j].Line })
+
+	printDiagnostics(os.Stderr, parser.FilePath, diags)
+
+	if config.HasErrors(diags) || (*strict && len(diags) > 0) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"mangomon/config"
+	"mangomon/internal/present"
+	"mangomon/internal/system"
+)
+
+// Switch implements `mangomon switch [--config FILE] <mode>`, meant to be
+// bound to a key in MangoWC
+func Switch(args []string) int {
+	fs := flag.NewFlagSet("switch", flag.ContinueOnError)
+	configPath := fs.String("config", "", "MangoWC config to update")
+	fs.Usage = func() {
+		var names []string
+		for _, p := range config.Presentations {
+			names = append(names, p.String())
+		}
+		fmt.Fprintf(fs.Output(), "Usage: mangomon switch [flags] <%s>\n", strings.Join(names, "|"))
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	mode, err := config.ParsePresentation(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	parser, err := config.NewParser(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing parser: %v\n", err)
+		return 2
+	}
+	rules, err := parser.Parse()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", parser.FilePath, err)
+		return 2
+	}
+
+	outputs, err := system.GetOutputs()
+	if err != nil {
+		if len(outputs) == 0 || outputs[0].Fabricated() {
+			fmt.Fprintf(os.Stderr, "Error: can't tell which outputs are connected: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %v, using the outputs the kernel reports as connected\n", err)
+	}
+	var names []string
+	for _, o := range outputs {
+		if _, ok := rules[o.Name]; !ok {
+			fmt.Fprintf(os.Stderr, "Warning: %s has no monitorrule, leaving it alone\n", o.Name)
+			continue
+		}
+		names = append(names, o.Name)
+	}
+
+	res, err := present.Switch(parser, rules, names, mode)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if res.Already {
+		fmt.Printf("Already in %s mode, nothing changed\n", mode)
+		return 0
+	}
+	fmt.Printf("Switched to %s", mode)
+	if len(res.Disabled) > 0 {
+		fmt.Printf(" (off: %s)", strings.Join(res.Disabled, ", "))
+	}
+	fmt.Println()
+	if res.LiveErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", res.LiveErr)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,231 @@
+package convert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"mangomon/config"
+)
+
+// stmt is one statement of a sway/kanshi style config, with the statements
+// of its { } block if it has one
+type stmt struct {
+	line  int
+	words []string
+	block []stmt
+}
+
+// parseBlocks splits a sway/kanshi config into statements. Comments, quotes
+// and line continuations are handled; everything else is left to the caller.
+func parseBlocks(src string) []stmt {
+	src = strings.ReplaceAll(src, "\\\n", " ")
+
+	type token struct {
+		text   string
+		line   int
+		quoted bool
+	}
+	var tokens []token
+	line := 1
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			i--
+		case c == '\n' || c == ';':
+			tokens = append(tokens, token{text: "\n", line: line})
+			if c == '\n' {
+				line++
+			}
+		case c == '{' || c == '}':
+			tokens = append(tokens, token{text: string(c), line: line})
+		case c == ' ' || c == '\t' || c == '\r':
+		case c == '"':
+			j := strings.IndexByte(src[i+1:], '"')
+			if j < 0 {
+				j = len(src) - i - 1
+			}
+			tokens = append(tokens, token{text: src[i+1 : i+1+j], line: line, quoted: true})
+			i += j + 1
+		default:
+			j := i
+			for j < len(src) && !strings.ContainsRune(" \t\r\n;{}#\"", rune(src[j])) {
+				j++
+			}
+			tokens = append(tokens, token{text: src[i:j], line: line})
+			i = j - 1
+		}
+	}
+
+	var parse func(pos int) ([]stmt, int)
+	parse = func(pos int) ([]stmt, int) {
+		var stmts []stmt
+		var cur stmt
+		flush := func() {
+			if len(cur.words) > 0 || cur.block != nil {
+				stmts = append(stmts, cur)
+			}
+			cur = stmt{}
+		}
+		for pos < len(tokens) {
+			t := tokens[pos]
+			pos++
+			switch {
+			case t.text == "\n" && !t.quoted:
+				flush()
+			case t.text == "{" && !t.quoted:
+				cur.block, pos = parse(pos)
+				if cur.block == nil {
+					cur.block = []stmt{}
+				}
+				if cur.line == 0 {
+					cur.line = t.line
+				}
+				flush()
+			case t.text == "}" && !t.quoted:
+				flush()
+				return stmts, pos
+			default:
+				if len(cur.words) == 0 {
+					cur.line = t.line
+				}
+				cur.words = append(cur.words, t.text)
+			}
+		}
+		flush()
+		return stmts, pos
+	}
+
+	stmts, _ := parse(0)
+	return stmts
+}
+
+// outputCommands are the sway and kanshi output sub-commands. Those
+// applyOutputCommands doesn't handle are skipped with their arguments, which
+// run up to the next sub-command.
+var outputCommands = map[string]bool{
+	"mode": true, "resolution": true, "res": true, "position": true, "pos": true,
+	"scale": true, "transform": true, "adaptive_sync": true,
+	"enable": true, "disable": true, "alias": true,
+	"bg": true, "background": true, "power": true, "dpms": true, "toggle": true,
+	"subpixel": true, "scale_filter": true, "max_render_time": true,
+	"render_bit_depth": true, "color_profile": true, "allow_tearing": true,
+	"hdr": true, "modeline": true,
+}
+
+// applyOutputCommands applies sway/kanshi output sub-commands to the rule.
+// It returns false if the output is disabled.
+func applyOutputCommands(rule *config.MonitorRule, words []string, warn func(string, ...any)) bool {
+	enabled := true
+	for i := 0; i < len(words); i++ {
+		arg := func() string {
+			if i+1 < len(words) {
+				i++
+				return words[i]
+			}
+			return ""
+		}
+
+		switch words[i] {
+		case "mode", "resolution", "res":
+			m := arg()
+			if m == "--custom" {
+				m = arg()
+			}
+			if w, h, rate, ok := parseMode(m); ok {
+				rule.Width, rule.Height, rule.RefreshRate = w, h, rate
+			} else {
+				warn("%s: invalid mode %q", rule.ID, m)
+			}
+		case "position", "pos":
+			p := arg()
+			xs, ys, found := strings.Cut(p, ",")
+			if !found {
+				// sway takes the coordinates as two words
+				xs, ys = p, arg()
+			}
+			x, errX := strconv.Atoi(xs)
+			y, errY := strconv.Atoi(ys)
+			if errX == nil && errY == nil {
+				rule.X, rule.Y = x, y
+			} else {
+				warn("%s: invalid position %q", rule.ID, p)
+			}
+		case "scale":
+			s := arg()
+			if v, err := strconv.ParseFloat(s, 64); err == nil && v > 0 {
+				rule.Scale = v
+			} else {
+				warn("%s: invalid scale %q", rule.ID, s)
+			}
+		case "transform":
+			t := arg()
+			if v, ok := parseTransform(t); ok {
+				rule.Transform = v
+			} else {
+				warn("%s: invalid transform %q", rule.ID, t)
+			}
+			if i+1 < len(words) && (words[i+1] == "clockwise" || words[i+1] == "anticlockwise") {
+				warn("%s: relative transform %q is not imported", rule.ID, words[i+1])
+				i++
+			}
+		case "adaptive_sync":
+			if arg() == "on" {
+				rule.VariableRefreshRate = 1
+			}
+		case "enable":
+			enabled = true
+		case "disable":
+			enabled = false
+		case "alias":
+			arg()
+		default:
+			// e.g. bg, power or max_render_time, with however many arguments
+			end := i + 1
+			for end < len(words) && !outputCommands[words[end]] {
+				end++
+			}
+			warn("%s: ignoring %s", rule.ID, strings.Join(words[i:end], " "))
+			i = end - 1
+		}
+	}
+	return enabled
+}
+
+// outputRule builds the rule for an "output NAME ..." statement. ok is false
+// when the output can't or shouldn't be imported.
+func outputRule(s stmt, warnings *[]string) (config.MonitorRule, bool) {
+	warn := func(format string, args ...any) {
+		*warnings = append(*warnings, fmt.Sprintf("line %d: ", s.line)+fmt.Sprintf(format, args...))
+	}
+	if len(s.words) < 2 {
+		warn("output without a name")
+		return config.MonitorRule{}, false
+	}
+
+	name := s.words[1]
+	if name == "*" || strings.Contains(name, " ") {
+		warn("skipping %q, only connector names can be imported", name)
+		return config.MonitorRule{}, false
+	}
+
+	rule := newRule(name)
+	enabled := applyOutputCommands(&rule, s.words[2:], warn)
+	for _, sub := range s.block {
+		warnSub := func(format string, args ...any) {
+			*warnings = append(*warnings, fmt.Sprintf("line %d: ", sub.line)+fmt.Sprintf(format, args...))
+		}
+		if !applyOutputCommands(&rule, sub.words, warnSub) {
+			enabled = false
+		}
+	}
+	if !enabled {
+		warn("skipping %s, disabled outputs are not imported", name)
+		return config.MonitorRule{}, false
+	}
+	return rule, true
+}
@@ -0,0 +1,99 @@
+// Package convert moves monitor layouts between mangomon and the config
+// formats of other Wayland tools.
+package convert
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"mangomon/config"
+)
+
+// Layout is a set of rules, optionally named after the profile it came from
+type Layout struct {
+	Name  string
+	Rules []config.MonitorRule
+}
+
+// ImportFormats lists the formats Import understands
+var ImportFormats = []string{"hyprland", "sway", "kanshi", "wlr-randr"}
+
+// Import reads a layout in the given format. Formats without profiles
+// return a single unnamed layout. Anything that can't be represented in a
+// monitorrule is skipped and reported in the returned warnings.
+func Import(format string, r io.Reader) ([]Layout, []string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch format {
+	case "hyprland":
+		rules, warnings := fromHyprland(string(data))
+		return []Layout{{Rules: rules}}, warnings, nil
+	case "sway":
+		rules, warnings := fromSway(string(data))
+		return []Layout{{Rules: rules}}, warnings, nil
+	case "kanshi":
+		layouts, warnings := fromKanshi(string(data))
+		return layouts, warnings, nil
+	case "wlr-randr":
+		rules, warnings, err := fromWlrRandr(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []Layout{{Rules: rules}}, warnings, nil
+	}
+	return nil, nil, fmt.Errorf("unknown format %q (supported: %s)", format, strings.Join(ImportFormats, ", "))
+}
+
+// newRule returns a rule with the same defaults mangomon uses for outputs
+// that have no rule yet
+func newRule(name string) config.MonitorRule {
+	return config.MonitorRule{
+		ID:    name,
+		Scale: 1.0,
+		Width: 1920, Height: 1080, RefreshRate: 60,
+	}
+}
+
+// transformNames are the wl_output transform names used by sway, kanshi and
+// wlr-randr, indexed by the value MangoWC expects in rr
+var transformNames = []string{
+	"normal", "90", "180", "270",
+	"flipped", "flipped-90", "flipped-180", "flipped-270",
+}
+
+func parseTransform(s string) (int, bool) {
+	for i, name := range transformNames {
+		if s == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// parseMode parses WxH, WxH@R and WxH@RHz
+func parseMode(s string) (w, h int, rate float64, ok bool) {
+	res, rateStr, hasRate := strings.Cut(s, "@")
+	ws, hs, found := strings.Cut(res, "x")
+	if !found {
+		return 0, 0, 0, false
+	}
+	w, errW := strconv.Atoi(ws)
+	h, errH := strconv.Atoi(hs)
+	if errW != nil || errH != nil {
+		return 0, 0, 0, false
+	}
+	rate = 60
+	if hasRate {
+		r, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSuffix(rateStr, "Hz"), "hz"), 64)
+		if err != nil {
+			return 0, 0, 0, false
+		}
+		rate = r
+	}
+	return w, h, rate, true
+}
@@ -0,0 +1,98 @@
+package convert
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"mangomon/config"
+)
+
+// ExportFormats lists the formats Export can write
+var ExportFormats = []string{"kanshi", "sway", "hyprland", "wlr-randr"}
+
+// Export writes the rules in the given format. name is used as the kanshi
+// profile name and ignored by the other formats.
+func Export(format string, w io.Writer, name string, rules []config.MonitorRule) error {
+	sorted := make([]config.MonitorRule, len(rules))
+	copy(sorted, rules)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	var b strings.Builder
+	switch format {
+	case "kanshi":
+		if name == "" {
+			name = "mangomon"
+		}
+		fmt.Fprintf(&b, "profile %s {\n", name)
+		for _, r := range sorted {
+			fmt.Fprintf(&b, "\toutput %s mode %s position %d,%d scale %g transform %s adaptive_sync %s\n",
+				r.ID, modeString(r), r.X, r.Y, r.Scale, transformName(r.Transform), onOff(r.VariableRefreshRate))
+		}
+		b.WriteString("}\n")
+
+	case "sway":
+		for _, r := range sorted {
+			fmt.Fprintf(&b, "output %s mode %s pos %d %d scale %g transform %s adaptive_sync %s\n",
+				r.ID, modeString(r), r.X, r.Y, r.Scale, transformName(r.Transform), onOff(r.VariableRefreshRate))
+		}
+
+	case "hyprland":
+		for _, r := range sorted {
+			fmt.Fprintf(&b, "monitor=%s,%dx%d@%g,%dx%d,%g,transform,%d,vrr,%d\n",
+				r.ID, r.Width, r.Height, r.RefreshRate, r.X, r.Y, r.Scale, r.Transform, r.VariableRefreshRate)
+		}
+
+	case "wlr-randr":
+		b.WriteString("#!/bin/sh\n# Generated by mangomon\nexec wlr-randr")
+		for _, r := range sorted {
+			sync := "disabled"
+			if r.VariableRefreshRate == 1 {
+				sync = "enabled"
+			}
+			fmt.Fprintf(&b, " \\\n\t--output %s --on --mode %s --pos %d,%d --scale %g --transform %s --adaptive-sync %s",
+				r.ID, modeString(r), r.X, r.Y, r.Scale, transformName(r.Transform), sync)
+		}
+		b.WriteString("\n")
+
+	default:
+		return fmt.Errorf("unknown format %q (supported: %s)", format, strings.Join(ExportFormats, ", "))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ExportFileName is a sensible file name for an export in the given format
+func ExportFileName(format string) string {
+	switch format {
+	case "kanshi":
+		return "kanshi.conf"
+	case "sway":
+		return "sway-outputs.conf"
+	case "hyprland":
+		return "hyprland-monitors.conf"
+	case "wlr-randr":
+		return "wlr-randr.sh"
+	}
+	return format
+}
+
+func modeString(r config.MonitorRule) string {
+	return fmt.Sprintf("%dx%d@%gHz", r.Width, r.Height, r.RefreshRate)
+}
+
+func transformName(t int) string {
+	if t >= 0 && t < len(transformNames) {
+		return transformNames[t]
+	}
+	return transformNames[0]
+}
+
+func onOff(v int) string {
+	if v == 1 {
+		return "on"
+	}
+	return "off"
+}
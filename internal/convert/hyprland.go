@@ -0,0 +1,101 @@
+package convert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"mangomon/config"
+)
+
+// fromHyprland reads monitor= lines:
+// monitor=NAME,RES,POS,SCALE[,transform,T][,vrr,V][,...]
+func fromHyprland(src string) ([]config.MonitorRule, []string) {
+	var rules []config.MonitorRule
+	var warnings []string
+
+	for i, line := range strings.Split(src, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		key, val, found := strings.Cut(line, "=")
+		if !found || strings.TrimSpace(key) != "monitor" {
+			continue
+		}
+		warn := func(format string, args ...any) {
+			warnings = append(warnings, fmt.Sprintf("line %d: ", i+1)+fmt.Sprintf(format, args...))
+		}
+
+		fields := strings.Split(val, ",")
+		for j := range fields {
+			fields[j] = strings.TrimSpace(fields[j])
+		}
+		name := fields[0]
+		if name == "" || strings.HasPrefix(name, "desc:") {
+			warn("skipping %q, only connector names can be imported", name)
+			continue
+		}
+		if len(fields) < 2 {
+			warn("skipping %s, no resolution", name)
+			continue
+		}
+		if fields[1] == "disable" || fields[1] == "disabled" {
+			warn("skipping %s, disabled outputs are not imported", name)
+			continue
+		}
+
+		rule := newRule(name)
+		if w, h, rate, ok := parseMode(fields[1]); ok {
+			rule.Width, rule.Height, rule.RefreshRate = w, h, rate
+		} else {
+			warn("%s: resolution %q is not a mode, using %dx%d@%.0f", name, fields[1], rule.Width, rule.Height, rule.RefreshRate)
+		}
+
+		if len(fields) > 2 {
+			xs, ys, found := strings.Cut(fields[2], "x")
+			x, errX := strconv.Atoi(xs)
+			y, errY := strconv.Atoi(ys)
+			if found && errX == nil && errY == nil {
+				rule.X, rule.Y = x, y
+			} else {
+				warn("%s: position %q is not absolute, using 0x0", name, fields[2])
+			}
+		}
+
+		if len(fields) > 3 {
+			if s, err := strconv.ParseFloat(fields[3], 64); err == nil && s > 0 {
+				rule.Scale = s
+			} else {
+				warn("%s: scale %q is not a number, using 1", name, fields[3])
+			}
+		}
+
+		// The rest are key,value pairs
+		for j := 4; j+1 < len(fields); j += 2 {
+			switch fields[j] {
+			case "transform":
+				if t, err := strconv.Atoi(fields[j+1]); err == nil && t >= 0 && t <= 7 {
+					rule.Transform = t
+				} else {
+					warn("%s: invalid transform %q", name, fields[j+1])
+				}
+			case "vrr":
+				switch fields[j+1] {
+				case "0":
+				case "1":
+					rule.VariableRefreshRate = 1
+				case "2":
+					rule.VariableRefreshRate = 1
+					warn("%s: fullscreen-only VRR is imported as always on", name)
+				default:
+					warn("%s: invalid vrr %q", name, fields[j+1])
+				}
+			case "mirror":
+				warn("%s: mirroring %s is not imported, use the mirror picker", name, fields[j+1])
+			default:
+				warn("%s: ignoring %s", name, fields[j])
+			}
+		}
+
+		rules = append(rules, rule)
+	}
+	return rules, warnings
+}
@@ -0,0 +1,40 @@
+package convert
+
+import "fmt"
+
+// fromKanshi reads profile blocks, each becoming a layout:
+// profile NAME { output NAME mode WxH@RHz position X,Y scale S ... }
+// Blocks without the profile keyword or a name get a generated name.
+func fromKanshi(src string) ([]Layout, []string) {
+	var layouts []Layout
+	var warnings []string
+
+	for _, s := range parseBlocks(src) {
+		if s.block == nil {
+			if len(s.words) > 0 && s.words[0] == "output" {
+				warnings = append(warnings, fmt.Sprintf("line %d: ignoring output outside a profile", s.line))
+			}
+			continue
+		}
+		if len(s.words) > 0 && s.words[0] != "profile" {
+			continue
+		}
+
+		name := fmt.Sprintf("kanshi-%d", len(layouts)+1)
+		if len(s.words) > 1 {
+			name = s.words[1]
+		}
+
+		layout := Layout{Name: name}
+		for _, sub := range s.block {
+			if len(sub.words) == 0 || sub.words[0] != "output" {
+				continue
+			}
+			if rule, ok := outputRule(sub, &warnings); ok {
+				layout.Rules = append(layout.Rules, rule)
+			}
+		}
+		layouts = append(layouts, layout)
+	}
+	return layouts, warnings
+}
@@ -0,0 +1,20 @@
+package convert
+
+import "mangomon/config"
+
+// fromSway reads output statements, inline or as blocks:
+// output NAME mode WxH@RHz pos X Y scale S transform T adaptive_sync on
+func fromSway(src string) ([]config.MonitorRule, []string) {
+	var rules []config.MonitorRule
+	var warnings []string
+
+	for _, s := range parseBlocks(src) {
+		if len(s.words) == 0 || s.words[0] != "output" {
+			continue
+		}
+		if rule, ok := outputRule(s, &warnings); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, warnings
+}
@@ -0,0 +1,64 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"mangomon/config"
+)
+
+// wlrRandrOutput is the subset of `wlr-randr --json` we use
+type wlrRandrOutput struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Modes   []struct {
+		Width   int     `json:"width"`
+		Height  int     `json:"height"`
+		Refresh float64 `json:"refresh"`
+		Current bool    `json:"current"`
+	} `json:"modes"`
+	Position struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	} `json:"position"`
+	Transform    string  `json:"transform"`
+	Scale        float64 `json:"scale"`
+	AdaptiveSync bool    `json:"adaptive_sync"`
+}
+
+func fromWlrRandr(data []byte) ([]config.MonitorRule, []string, error) {
+	var outputs []wlrRandrOutput
+	if err := json.Unmarshal(data, &outputs); err != nil {
+		return nil, nil, fmt.Errorf("parsing wlr-randr JSON: %w", err)
+	}
+
+	var rules []config.MonitorRule
+	var warnings []string
+	for _, o := range outputs {
+		if !o.Enabled {
+			warnings = append(warnings, fmt.Sprintf("skipping %s, disabled outputs are not imported", o.Name))
+			continue
+		}
+
+		rule := newRule(o.Name)
+		for _, m := range o.Modes {
+			if m.Current {
+				rule.Width, rule.Height, rule.RefreshRate = m.Width, m.Height, m.Refresh
+			}
+		}
+		rule.X, rule.Y = o.Position.X, o.Position.Y
+		if o.Scale > 0 {
+			rule.Scale = o.Scale
+		}
+		if t, ok := parseTransform(o.Transform); ok {
+			rule.Transform = t
+		} else if o.Transform != "" {
+			warnings = append(warnings, fmt.Sprintf("%s: invalid transform %q", o.Name, o.Transform))
+		}
+		if o.AdaptiveSync {
+			rule.VariableRefreshRate = 1
+		}
+		rules = append(rules, rule)
+	}
+	return rules, warnings, nil
+}
@@ -0,0 +1,129 @@
+// Package cvt computes VESA Coordinated Video Timings, the same way the
+// cvt(1) tool and libxcvt do, for progressive modes without margins.
+package cvt
+
+import "fmt"
+
+// Timing is a computed mode. Clock is in kHz.
+type Timing struct {
+	Clock                                  int
+	HDisplay, HSyncStart, HSyncEnd, HTotal int
+	VDisplay, VSyncStart, VSyncEnd, VTotal int
+	Reduced                                bool
+}
+
+const (
+	cellGranularity = 8
+	clockStep       = 250 // kHz
+	minVPorch       = 3
+	minVBackPorch   = 6
+
+	// Normal blanking
+	minVSyncBP   = 550.0 // µs
+	hSyncPercent = 8
+	cPrime       = (40-20)*128/256 + 20
+	mPrime       = 600 * 128 / 256
+
+	// Reduced blanking
+	rbMinVBlank = 460.0 // µs
+	rbHSync     = 32
+	rbHBlank    = 160
+	rbVFPorch   = 3
+)
+
+// vsyncWidth depends on the aspect ratio, other ratios get 10 lines
+func vsyncWidth(w, h int) int {
+	switch {
+	case h%3 == 0 && h*4/3 == w:
+		return 4
+	case h%9 == 0 && h*16/9 == w:
+		return 5
+	case h%10 == 0 && h*16/10 == w:
+		return 6
+	case h%4 == 0 && h*5/4 == w, h%9 == 0 && h*15/9 == w:
+		return 7
+	}
+	return 10
+}
+
+// Compute returns CVT timings for the mode, with reduced blanking if asked.
+// Reduced blanking needs far less bandwidth and is what digital panels
+// usually want; normal blanking is kept for CRT-era compatibility.
+func Compute(width, height int, refresh float64, reduced bool) Timing {
+	t := Timing{Reduced: reduced}
+	if width <= 0 || height <= 0 || refresh <= 0 {
+		return t
+	}
+
+	t.HDisplay = width - width%cellGranularity
+	t.VDisplay = height
+	vsync := vsyncWidth(width, height)
+
+	var hPeriod float64 // µs
+	if !reduced {
+		hPeriod = (1000000.0/refresh - minVSyncBP) / float64(t.VDisplay+minVPorch)
+
+		vSyncBP := int(minVSyncBP/hPeriod) + 1
+		if vSyncBP < vsync+minVPorch {
+			vSyncBP = vsync + minVPorch
+		}
+		t.VTotal = t.VDisplay + vSyncBP + minVPorch
+
+		hBlankPercent := cPrime - mPrime*hPeriod/1000.0
+		if hBlankPercent < 20 {
+			hBlankPercent = 20
+		}
+		hBlank := int(float64(t.HDisplay) * hBlankPercent / (100.0 - hBlankPercent))
+		hBlank -= hBlank % (2 * cellGranularity)
+		t.HTotal = t.HDisplay + hBlank
+
+		t.HSyncEnd = t.HDisplay + hBlank/2
+		hSyncWidth := t.HTotal * hSyncPercent / 100
+		hSyncWidth -= hSyncWidth % cellGranularity
+		t.HSyncStart = t.HSyncEnd - hSyncWidth
+
+		t.VSyncStart = t.VDisplay + minVPorch
+		t.VSyncEnd = t.VSyncStart + vsync
+	} else {
+		hPeriod = (1000000.0/refresh - rbMinVBlank) / float64(t.VDisplay)
+
+		vbiLines := int(rbMinVBlank/hPeriod) + 1
+		if vbiLines < rbVFPorch+vsync+minVBackPorch {
+			vbiLines = rbVFPorch + vsync + minVBackPorch
+		}
+		t.VTotal = t.VDisplay + vbiLines
+		t.HTotal = t.HDisplay + rbHBlank
+
+		t.HSyncEnd = t.HDisplay + rbHBlank/2
+		t.HSyncStart = t.HSyncEnd - rbHSync
+
+		t.VSyncStart = t.VDisplay + rbVFPorch
+		t.VSyncEnd = t.VSyncStart + vsync
+	}
+	t.Clock = int(float64(t.HTotal) * 1000.0 / hPeriod)
+	t.Clock -= t.Clock % clockStep
+	return t
+}
+
+// Refresh is the rate the timing actually produces, which differs slightly
+// from the requested one because the clock is rounded
+func (t Timing) Refresh() float64 {
+	if t.HTotal == 0 || t.VTotal == 0 {
+		return 0
+	}
+	return float64(t.Clock) * 1000.0 / float64(t.HTotal*t.VTotal)
+}
+
+// Modeline formats the timing like cvt(1) does
+func (t Timing) Modeline(requested float64) string {
+	name := fmt.Sprintf("%dx%d_%.2f", t.HDisplay, t.VDisplay, requested)
+	sync := "-hsync +vsync"
+	if t.Reduced {
+		name = fmt.Sprintf("%dx%dR", t.HDisplay, t.VDisplay)
+		sync = "+hsync -vsync"
+	}
+	return fmt.Sprintf("Modeline %q %.2f  %d %d %d %d  %d %d %d %d %s",
+		name, float64(t.Clock)/1000.0,
+		t.HDisplay, t.HSyncStart, t.HSyncEnd, t.HTotal,
+		t.VDisplay, t.VSyncStart, t.VSyncEnd, t.VTotal, sync)
+}
@@ -0,0 +1,141 @@
+// Package present applies presentation modes (extend, mirror, external or
+// internal only) for both `mangomon switch` and the TUI switcher.
+package present
+
+import (
+	"errors"
+	"fmt"
+
+	"mangomon/config"
+	"mangomon/internal/state"
+	"mangomon/internal/system"
+)
+
+// Result says what a switch did
+type Result struct {
+	Disabled []string // Outputs turned off
+	LiveErr  error    // Applying to the running compositor failed; the config was still saved
+	Already  bool     // The mode was already active, nothing was changed
+}
+
+// Switch rearranges rules for the mode, saves them to the config and
+// applies the result to the running compositor. Only the given (connected)
+// outputs are touched. Leaving extend remembers the layout so switching
+// back restores it instead of auto-arranging; switching to extend when the
+// monitors are already side by side changes nothing.
+func Switch(parser *config.ConfigParser, rules map[string]config.MonitorRule, outputs []string, mode config.Presentation) (Result, error) {
+	st, err := state.Load()
+	if err != nil {
+		return Result{}, err
+	}
+
+	wasExtended := st.Presentation == "" || st.Presentation == config.PresentExtend.String()
+	// There is nothing to go back to, and re-packing would throw away a
+	// layout arranged by hand
+	if wasExtended && mode == config.PresentExtend && len(st.Disabled) == 0 && sideBySide(rules, outputs) {
+		return Result{Already: true}, nil
+	}
+	if wasExtended && mode != config.PresentExtend {
+		remember(&st, rules, outputs)
+	}
+
+	var disabled []string
+	if mode == config.PresentExtend && restore(rules, outputs, st.PreviousLayout) {
+		st.PreviousLayout = nil
+	} else if disabled, err = config.Present(rules, outputs, mode); err != nil {
+		return Result{}, err
+	}
+
+	var list []config.MonitorRule
+	for _, r := range rules {
+		list = append(list, r)
+	}
+	if err := parser.Save(list); err != nil {
+		return Result{}, err
+	}
+	if _, err := parser.Parse(); err != nil {
+		return Result{}, err
+	}
+
+	st.Presentation = mode.String()
+	st.Disabled = disabled
+	if err := state.Save(st); err != nil {
+		return Result{}, err
+	}
+
+	res := Result{Disabled: disabled}
+	off := make(map[string]bool)
+	for _, id := range disabled {
+		off[id] = true
+	}
+	var errs []error
+	for _, id := range outputs {
+		if err := system.SetOutputEnabled(id, !off[id]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := system.ReloadConfig(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		res.LiveErr = fmt.Errorf("saved, but applying live failed: %w", errors.Join(errs...))
+	}
+	return res, nil
+}
+
+// Mirrored records that monitors were mirrored by hand, e.g. in the mirror
+// picker, so that switching to extend lays them out again. Like leaving
+// extend with Switch, it remembers the layout from before.
+func Mirrored(before map[string]config.MonitorRule, outputs []string) error {
+	st, err := state.Load()
+	if err != nil {
+		return err
+	}
+	if st.Presentation == "" || st.Presentation == config.PresentExtend.String() {
+		remember(&st, before, outputs)
+	}
+	st.Presentation = config.PresentMirror.String()
+	return state.Save(st)
+}
+
+// remember keeps the extended layout of the outputs to restore later
+func remember(st *state.AppState, rules map[string]config.MonitorRule, outputs []string) {
+	st.PreviousLayout = make(map[string]config.MonitorRule)
+	for _, id := range outputs {
+		if r, ok := rules[id]; ok {
+			st.PreviousLayout[id] = r
+		}
+	}
+}
+
+// sideBySide reports whether none of the outputs overlap, i.e. none mirror
+// another
+func sideBySide(rules map[string]config.MonitorRule, outputs []string) bool {
+	for i, a := range outputs {
+		for _, b := range outputs[i+1:] {
+			ra, okA := rules[a]
+			rb, okB := rules[b]
+			if okA && okB && ra.Rect().Overlaps(rb.Rect()) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// restore puts back the remembered extended layout if it covers every
+// connected output, and reports whether it did
+func restore(rules map[string]config.MonitorRule, outputs []string, previous map[string]config.MonitorRule) bool {
+	if len(previous) == 0 || len(outputs) == 0 {
+		return false
+	}
+	for _, id := range outputs {
+		if _, ok := previous[id]; !ok {
+			return false
+		}
+	}
+	for _, id := range outputs {
+		rules[id] = previous[id]
+	}
+	return true
+}
@@ -4,18 +4,66 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+
+	"mangomon/config"
 )
 
 type AppState struct {
 	GridSize int `json:"grid_size"`
+
+	// Canvas viewport
+	Zoom float64 `json:"zoom,omitempty"`
+	PanX int     `json:"pan_x,omitempty"`
+	PanY int     `json:"pan_y,omitempty"`
+
+	// Presentation mode set by `mangomon switch`, the outputs it turned off
+	// and the extended layout to restore when switching back to extend
+	Presentation   string                        `json:"presentation,omitempty"`
+	Disabled       []string                      `json:"disabled,omitempty"`
+	PreviousLayout map[string]config.MonitorRule `json:"previous_layout,omitempty"`
+
+	// Last saved settings of each physical monitor, by EDID identity (and
+	// connector, for monitors without a serial)
+	Monitors map[string]MonitorMemory `json:"monitors,omitempty"`
 }
 
-func GetStatePath() string {
+// MonitorMemory is what mangomon applies to a monitor that shows up without
+// a rule. The rule's ID is unused; the anchor's target is the key the other
+// monitor is remembered under, since connector names change between machines.
+type MonitorMemory struct {
+	Rule      config.MonitorRule `json:"rule"`
+	Connector string             `json:"connector"` // Where it was last seen, for reference
+}
+
+// dirOverride replaces ~/.config/mangomon, see SetDir
+var dirOverride string
+
+// SetDir keeps mangomon's own files in dir instead of ~/.config/mangomon,
+// e.g. while replaying a capture so the real state isn't touched
+func SetDir(dir string) {
+	dirOverride = dir
+}
+
+// appFile returns a file in mangomon's directory, or the bare name if the
+// home directory is unknown
+func appFile(name string) string {
+	if dirOverride != "" {
+		return filepath.Join(dirOverride, name)
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
-		return "state.json"
+		return name
 	}
-	return filepath.Join(home, ".config", "mangomon", "state.json")
+	return filepath.Join(home, ".config", "mangomon", name)
+}
+
+func GetStatePath() string {
+	return appFile("state.json")
+}
+
+// GetLibraryPath is the user-editable file of per-model default rules
+func GetLibraryPath() string {
+	return appFile("library.conf")
 }
 
 func Load() (AppState, error) {
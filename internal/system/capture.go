@@ -0,0 +1,74 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// connectorFiles are the files read from each /sys/class/drm connector
+var connectorFiles = []string{"status", "modes", "edid", "enabled"}
+
+// Capture copies everything this package reads into dir, in a layout
+// Replay understands: mmsg output and the sysfs and debugfs files under
+// dir/sys. Files that can't be read, e.g. debugfs without root, are left
+// out. It returns the number of connectors captured.
+func Capture(dir string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if out, err := mmsgOutputs(ctx); err == nil {
+		if err := os.WriteFile(filepath.Join(dir, mmsgOutputsFile), out, 0644); err != nil {
+			return 0, err
+		}
+	} else if err := os.WriteFile(filepath.Join(dir, mmsgErrorFile), []byte(err.Error()+"\n"), 0644); err != nil {
+		return 0, err
+	}
+
+	entries, err := os.ReadDir(drmRoot)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", drmRoot, err)
+	}
+	n := 0
+	for _, e := range entries {
+		connector := e.Name()
+		copied := false
+		for _, name := range connectorFiles {
+			data, err := os.ReadFile(filepath.Join(drmRoot, connector, name))
+			if err != nil {
+				continue
+			}
+			if err := writeCaptured(dir, filepath.Join("sys", "class", "drm", connector, name), data); err != nil {
+				return n, err
+			}
+			copied = true
+		}
+		if !copied {
+			continue // Not a connector, e.g. card1 or version
+		}
+		n++
+
+		// card1-DP-1 -> dri/1/DP-1/vrr_range, see GetVRRInfo
+		card, output, ok := strings.Cut(connector, "-")
+		if !ok {
+			continue
+		}
+		rel := filepath.Join(strings.TrimPrefix(card, "card"), output, "vrr_range")
+		if data, err := os.ReadFile(filepath.Join(debugRoot, rel)); err == nil {
+			if err := writeCaptured(dir, filepath.Join("sys", "kernel", "debug", "dri", rel), data); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+func writeCaptured(dir, rel string, data []byte) error {
+	path := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
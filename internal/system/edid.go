@@ -0,0 +1,301 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// EDID holds what mangomon uses from a monitor's EDID
+type EDID struct {
+	Manufacturer string // PNP ID, e.g. "DEL"
+	ProductCode  uint16
+	SerialNumber uint32
+	Serial       string // Serial number descriptor, often more useful than SerialNumber
+	Name         string // Monitor name descriptor, e.g. "DELL U2723QE"
+
+	WidthMM, HeightMM int
+
+	Preferred *Mode  // First detailed timing, the native mode
+	Timings   []Mode // Every detailed timing, the only modes with a known rate
+
+	// Vertical refresh range. MinRate and MaxRate come from the range limits
+	// descriptor or a vendor block; AdaptiveSync is set when the monitor
+	// advertises the range as variable rather than just supported rates.
+	MinRate, MaxRate float64
+	AdaptiveSync     bool
+}
+
+// minVRRSpan is how many Hz the variable range must span; the kernel
+// doesn't enable adaptive sync for narrower ranges either
+const minVRRSpan = 10
+
+var edidHeader = []byte{0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00}
+
+// ParseEDID decodes the base block and any CTA-861 extensions
+func ParseEDID(data []byte) (EDID, error) {
+	var e EDID
+	if len(data) < 128 || string(data[:8]) != string(edidHeader) {
+		return e, errors.New("not an EDID")
+	}
+
+	mfg := uint16(data[8])<<8 | uint16(data[9])
+	e.Manufacturer = string([]byte{
+		byte('A' - 1 + (mfg>>10)&0x1f),
+		byte('A' - 1 + (mfg>>5)&0x1f),
+		byte('A' - 1 + mfg&0x1f),
+	})
+	e.ProductCode = uint16(data[10]) | uint16(data[11])<<8
+	e.SerialNumber = uint32(data[12]) | uint32(data[13])<<8 | uint32(data[14])<<16 | uint32(data[15])<<24
+	e.WidthMM = int(data[21]) * 10
+	e.HeightMM = int(data[22]) * 10
+	edid14 := data[18] == 1 && data[19] >= 4
+	continuous := data[0x18]&0x01 != 0 // Continuous frequency, EDID 1.4 only
+
+	for i := 0; i < 4; i++ {
+		d := data[54+i*18 : 54+(i+1)*18]
+		clock := int(d[0]) | int(d[1])<<8
+		if clock != 0 {
+			mode, wmm, hmm := parseDetailedTiming(d)
+			if e.Preferred == nil {
+				mode.Preferred = true
+				e.Preferred = &mode
+				// Detailed timings give the size in mm instead of cm
+				if wmm > 0 && hmm > 0 {
+					e.WidthMM, e.HeightMM = wmm, hmm
+				}
+			}
+			e.Timings = append(e.Timings, mode)
+			continue
+		}
+
+		switch d[3] {
+		case 0xfc:
+			e.Name = descriptorText(d)
+		case 0xff:
+			e.Serial = descriptorText(d)
+		case 0xfd:
+			minV, maxV := int(d[5]), int(d[6])
+			if edid14 && d[4]&0x01 != 0 {
+				minV += 255
+			}
+			if edid14 && d[4]&0x02 != 0 {
+				maxV += 255
+			}
+			e.MinRate, e.MaxRate = float64(minV), float64(maxV)
+			// "Range limits only" alone is common on fixed-rate monitors.
+			// Adaptive sync ones also set the continuous frequency feature
+			// bit, and the range has to be wide enough to be of use.
+			if edid14 && continuous && d[10] == 0x01 && maxV-minV > minVRRSpan {
+				e.AdaptiveSync = true
+			}
+		}
+	}
+
+	for ext := 1; ext <= int(data[126]) && len(data) >= (ext+1)*128; ext++ {
+		parseCTA(data[ext*128:(ext+1)*128], &e)
+	}
+	return e, nil
+}
+
+func parseDetailedTiming(d []byte) (mode Mode, widthMM, heightMM int) {
+	clock := (int(d[0]) | int(d[1])<<8) * 10000
+	hActive := int(d[2]) | int(d[4]&0xf0)<<4
+	hBlank := int(d[3]) | int(d[4]&0x0f)<<8
+	vActive := int(d[5]) | int(d[7]&0xf0)<<4
+	vBlank := int(d[6]) | int(d[7]&0x0f)<<8
+	widthMM = int(d[12]) | int(d[14]&0xf0)<<4
+	heightMM = int(d[13]) | int(d[14]&0x0f)<<8
+
+	mode = Mode{Width: hActive, Height: vActive, Interlaced: d[17]&0x80 != 0}
+	if total := (hActive + hBlank) * (vActive + vBlank); total > 0 {
+		mode.Rate = float64(clock) / float64(total)
+	}
+	// Interlaced timings describe a field, the frame has twice the lines
+	if mode.Interlaced {
+		mode.Height *= 2
+	}
+	return mode, widthMM, heightMM
+}
+
+func descriptorText(d []byte) string {
+	text := string(d[5:18])
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	return strings.TrimSpace(text)
+}
+
+// Timing returns the detailed timing for a mode size, if the EDID has one
+func (e EDID) Timing(width, height int, interlaced bool) (Mode, bool) {
+	for _, t := range e.Timings {
+		if t.Width == width && t.Height == height && t.Interlaced == interlaced {
+			return t, true
+		}
+	}
+	return Mode{}, false
+}
+
+// parseCTA collects the detailed timings after the data blocks and looks for
+// vendor blocks that advertise a variable refresh range: AMD FreeSync and
+// the HDMI Forum VRR fields
+func parseCTA(b []byte, e *EDID) {
+	if b[0] != 0x02 {
+		return
+	}
+	end := int(b[2])
+	if end > len(b) {
+		end = len(b)
+	}
+	if end >= 4 {
+		for i := end; i+18 <= 127; i += 18 {
+			if d := b[i : i+18]; d[0] != 0 || d[1] != 0 {
+				mode, _, _ := parseDetailedTiming(d)
+				e.Timings = append(e.Timings, mode)
+			}
+		}
+	}
+	for i := 4; i < end; {
+		tag, length := b[i]>>5, int(b[i]&0x1f)
+		if i+1+length > end {
+			break
+		}
+		p := b[i+1 : i+1+length]
+		if tag == 3 && length >= 3 {
+			oui := uint32(p[0]) | uint32(p[1])<<8 | uint32(p[2])<<16
+			switch {
+			case oui == 0x00001a && length >= 7: // AMD
+				if p[5] > 0 && int(p[6])-int(p[5]) > minVRRSpan {
+					e.MinRate, e.MaxRate = float64(p[5]), float64(p[6])
+					e.AdaptiveSync = true
+				}
+			case oui == 0xc45dd8 && length >= 10: // HDMI Forum
+				minV := int(p[8] & 0x3f)
+				maxV := int(p[8]&0xc0)<<2 | int(p[9])
+				if minV > 0 && maxV-minV > minVRRSpan {
+					e.MinRate, e.MaxRate = float64(minV), float64(maxV)
+					e.AdaptiveSync = true
+				}
+			}
+		}
+		i += 1 + length
+	}
+}
+
+// findConnector returns the /sys/class/drm directory of an output such as
+// card1-DP-1 for DP-1
+func findConnector(output string) (string, error) {
+	sysPath := drmRoot
+	files, err := os.ReadDir(sysPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", sysPath, err)
+	}
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), "-"+output) {
+			return sysPath + "/" + f.Name(), nil
+		}
+	}
+	return "", fmt.Errorf("could not find drm folder for output %s", output)
+}
+
+// ConnectorStatus summarises the status of every DRM connector, e.g.
+// "card1-DP-1=connected card1-HDMI-A-1=disconnected". It changes when a
+// monitor is plugged in or out, and is cheap enough to poll.
+func ConnectorStatus() (string, error) {
+	sysPath := drmRoot
+	files, err := os.ReadDir(sysPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", sysPath, err)
+	}
+	var parts []string
+	for _, f := range files {
+		status, err := os.ReadFile(filepath.Join(sysPath, f.Name(), "status"))
+		if err != nil {
+			continue // Not a connector, e.g. card1 or version
+		}
+		parts = append(parts, f.Name()+"="+strings.TrimSpace(string(status)))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " "), nil
+}
+
+// ConnectedOutputs lists the connectors the kernel reports as connected, e.g.
+// DP-1 for card1-DP-1
+func ConnectedOutputs() ([]Output, error) {
+	sysPath := drmRoot
+	files, err := os.ReadDir(sysPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", sysPath, err)
+	}
+	var outputs []Output
+	for _, f := range files {
+		status, err := os.ReadFile(filepath.Join(sysPath, f.Name(), "status"))
+		if err != nil || strings.TrimSpace(string(status)) != "connected" {
+			continue
+		}
+		// card1-DP-1 -> DP-1
+		if _, name, ok := strings.Cut(f.Name(), "-"); ok {
+			outputs = append(outputs, Output{Name: name, Source: SourceSysfs})
+		}
+	}
+	return outputs, nil
+}
+
+// GetEDID reads and parses the EDID of a connected output
+func GetEDID(output string) (EDID, error) {
+	dir, err := findConnector(output)
+	if err != nil {
+		return EDID{}, err
+	}
+	data, err := os.ReadFile(dir + "/edid")
+	if err != nil {
+		return EDID{}, fmt.Errorf("failed to read EDID of %s: %w", output, err)
+	}
+	if len(data) == 0 {
+		return EDID{}, fmt.Errorf("%s has no EDID (disconnected?)", output)
+	}
+	return ParseEDID(data)
+}
+
+// Identity tells physical monitors apart across connectors and machines:
+// manufacturer, product code and serial. Monitors that report no serial
+// share the identity of their model.
+func (e EDID) Identity() string {
+	id := fmt.Sprintf("%s-%04X", e.Manufacturer, e.ProductCode)
+	switch {
+	case e.Serial != "":
+		id += "-" + e.Serial
+	case e.SerialNumber != 0:
+		id += fmt.Sprintf("-%d", e.SerialNumber)
+	}
+	return id
+}
+
+// Diagonal is the screen size in inches, 0 if the EDID doesn't report a
+// physical size
+func (e EDID) Diagonal() float64 {
+	if e.WidthMM <= 0 || e.HeightMM <= 0 {
+		return 0
+	}
+	return math.Hypot(float64(e.WidthMM), float64(e.HeightMM)) / 25.4
+}
+
+// PPI is the physical pixel density for a mode of the given size, 0 if the
+// EDID doesn't report a physical size
+func (e EDID) PPI(width, height int) float64 {
+	diagIn := e.Diagonal()
+	if diagIn == 0 {
+		return 0
+	}
+	return math.Hypot(float64(width), float64(height)) / diagIn
+}
+
+// Models are the names a model rule can use for this monitor: the monitor
+// name descriptor and the product code in hex
+func (e EDID) Models() []string {
+	return []string{e.Name, fmt.Sprintf("%04X", e.ProductCode)}
+}
@@ -0,0 +1,43 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseEDID(t *testing.T) {
+	tests := []struct {
+		file             string
+		adaptiveSync     bool
+		minRate, maxRate float64
+	}{
+		// VRR only in the HDMI Forum block, the range descriptor says
+		// "range limits only"
+		{"hdmi-forum-vrr.bin", true, 48, 144},
+		// EDID 1.4 range limits without the continuous frequency bit
+		{"fixed-rate.bin", false, 48, 75},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			e, err := ParseEDID(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.Manufacturer != "TST" || e.Name != "TEST MONITOR" {
+				t.Errorf("got manufacturer %q, name %q", e.Manufacturer, e.Name)
+			}
+			if p := e.Preferred; p == nil || p.Width != 2560 || p.Height != 1440 {
+				t.Errorf("got preferred mode %+v, want 2560x1440", p)
+			}
+			if e.AdaptiveSync != tt.adaptiveSync || e.MinRate != tt.minRate || e.MaxRate != tt.maxRate {
+				t.Errorf("got adaptive sync %v %g-%g Hz, want %v %g-%g Hz",
+					e.AdaptiveSync, e.MinRate, e.MaxRate, tt.adaptiveSync, tt.minRate, tt.maxRate)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package system
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -9,17 +10,38 @@ import (
 )
 
 type Output struct {
-	Name string
+	Name   string
+	Source Source
 }
 
+// Fabricated reports whether the output is an example rather than
+// something that was found
+func (o Output) Fabricated() bool {
+	return o.Source == SourceFallback
+}
+
+// GetOutputs lists the outputs MangoWC knows about
 func GetOutputs() ([]Output, error) {
-	cmd := exec.Command("mmsg", "-O")
-	outputBytes, err := cmd.Output()
+	return GetOutputsContext(context.Background())
+}
+
+// GetOutputsContext is GetOutputs with a deadline for mmsg. If mmsg fails
+// it returns the error along with the connectors the kernel sees as
+// connected, or failing that example outputs; Source tells them apart.
+func GetOutputsContext(ctx context.Context) ([]Output, error) {
+	outputBytes, err := mmsgOutputs(ctx)
 	if err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		err = fmt.Errorf("mmsg -O: %w", err)
+		if outputs, sysErr := ConnectedOutputs(); sysErr == nil && len(outputs) > 0 {
+			return outputs, err
+		}
 		return []Output{
-			{Name: "eDP-1"},
-			{Name: "HDMI-A-1"},
-		}, nil
+			{Name: "eDP-1", Source: SourceFallback},
+			{Name: "HDMI-A-1", Source: SourceFallback},
+		}, err
 	}
 
 	outputStr := string(outputBytes)
@@ -32,7 +54,7 @@ func GetOutputs() ([]Output, error) {
 
 			parts := strings.Fields(trimmed)
 			if len(parts) > 0 {
-				outputs = append(outputs, Output{Name: parts[0]})
+				outputs = append(outputs, Output{Name: parts[0], Source: SourceLive})
 			}
 		}
 	}
@@ -40,43 +62,66 @@ func GetOutputs() ([]Output, error) {
 	return outputs, nil
 }
 
+// dispatch runs a MangoWC dispatcher through mmsg, e.g. "reload_config"
+func dispatch(args ...string) error {
+	if replayDir != "" {
+		return errReplaying
+	}
+	out, err := exec.Command("mmsg", "-d", strings.Join(args, ",")).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("mmsg -d %s: %s", strings.Join(args, ","), msg)
+		}
+		return fmt.Errorf("mmsg -d %s: %w", strings.Join(args, ","), err)
+	}
+	return nil
+}
+
+// SetOutputEnabled turns an output on or off in the running compositor.
+// monitorrule has no key for this, so it only lasts until MangoWC restarts.
+func SetOutputEnabled(output string, enabled bool) error {
+	if enabled {
+		return dispatch("enable_monitor", output)
+	}
+	return dispatch("disable_monitor", output)
+}
+
+// ReloadConfig makes MangoWC apply the saved config
+func ReloadConfig() error {
+	return dispatch("reload_config")
+}
+
 // Mode represents a display mode
 type Mode struct {
 	Width, Height int
 	Rate          float64
+	Preferred     bool // The monitor's native mode
+	Interlaced    bool
+	VRR           bool // Rate is within the monitor's adaptive sync range
+	Source        Source
 }
 
 func GetModes(output string) ([]Mode, error) {
-	sysPath := "/sys/class/drm"
-	files, err := os.ReadDir(sysPath)
+	dir, err := findConnector(output)
 	if err != nil {
-		return getFallbackModes(), fmt.Errorf("failed to read %s: %w", sysPath, err)
-	}
-
-	var modeFile string
-	for _, f := range files {
-		name := f.Name()
-		if strings.HasSuffix(name, "-"+output) {
-			modeFile = fmt.Sprintf("%s/%s/modes", sysPath, name)
-			break
-		}
-	}
-
-	if modeFile == "" {
-		return getFallbackModes(), fmt.Errorf("could not find drm folder for output %s", output)
+		return getFallbackModes(), err
 	}
+	modeFile := dir + "/modes"
 
 	content, err := os.ReadFile(modeFile)
 	if err != nil {
 		return getFallbackModes(), fmt.Errorf("failed to read modes file %s: %w", modeFile, err)
 	}
 
+	// Best effort, only used to refine the list
+	edid, _ := GetEDID(output)
+
 	lines := strings.Split(string(content), "\n")
 	var modes []Mode
 
 	seen := make(map[string]bool)
 
-	for _, line := range lines {
+	for i, line := range lines {
 		line = strings.TrimSpace(line)
 		if line == "" {
 			continue
@@ -91,16 +136,41 @@ func GetModes(output string) ([]Mode, error) {
 		if len(parts) != 2 {
 			continue
 		}
+		interlaced := strings.HasSuffix(parts[1], "i")
 		w, _ := strconv.Atoi(parts[0])
-		h, _ := strconv.Atoi(parts[1])
-
-		modes = append(modes, Mode{Width: w, Height: h, Rate: 60.0})
+		h, _ := strconv.Atoi(strings.TrimSuffix(parts[1], "i"))
+		// The kernel lists the preferred mode first
+		preferred := i == 0
+
+		// The modes file has no rates. Only detailed timings in the EDID
+		// give one; otherwise 60 Hz is a guess like the higher rates, which
+		// are common values the monitor may not support.
+		rates, known := []float64{60.0}, false
+		if t, ok := edid.Timing(w, h, interlaced); ok {
+			rates, known = []float64{t.Rate}, true
+		}
+		if w >= 1920 && !interlaced {
+			rates = append(rates, 120.0, 144.0, 165.0, 240.0)
+		}
 
-		if w >= 1920 {
-			modes = append(modes, Mode{Width: w, Height: h, Rate: 120.0})
-			modes = append(modes, Mode{Width: w, Height: h, Rate: 144.0})
-			modes = append(modes, Mode{Width: w, Height: h, Rate: 165.0})
-			modes = append(modes, Mode{Width: w, Height: h, Rate: 240.0})
+		for j, rate := range rates {
+			// Don't offer rates the monitor says it can't do
+			if j > 0 && edid.MaxRate > 0 && rate > edid.MaxRate+0.5 {
+				continue
+			}
+			source := SourceSysfs
+			if j > 0 || !known {
+				source = SourceFallback
+			}
+			modes = append(modes, Mode{
+				Width:      w,
+				Height:     h,
+				Rate:       rate,
+				Preferred:  preferred && j == 0,
+				Interlaced: interlaced,
+				VRR:        edid.AdaptiveSync && rate >= edid.MinRate && rate <= edid.MaxRate+0.5,
+				Source:     source,
+			})
 		}
 	}
 
@@ -112,7 +182,7 @@ func GetModes(output string) ([]Mode, error) {
 }
 
 func getFallbackModes() []Mode {
-	return []Mode{
+	modes := []Mode{
 		{Width: 3840, Height: 2160, Rate: 144.0},
 		{Width: 3840, Height: 2160, Rate: 60.0},
 		{Width: 2560, Height: 1440, Rate: 165.0},
@@ -121,4 +191,8 @@ func getFallbackModes() []Mode {
 		{Width: 1920, Height: 1080, Rate: 144.0},
 		{Width: 1920, Height: 1080, Rate: 60.0},
 	}
+	for i := range modes {
+		modes[i].Source = SourceFallback
+	}
+	return modes
 }
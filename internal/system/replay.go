@@ -0,0 +1,60 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Where hardware information is read from. Replay points them at a capture.
+var (
+	drmRoot   = "/sys/class/drm"
+	debugRoot = "/sys/kernel/debug/dri"
+	replayDir string // Set while replaying, mmsg isn't run then
+)
+
+// Files of a capture, next to the sys tree
+const (
+	mmsgOutputsFile = "mmsg-O.txt"
+	mmsgErrorFile   = "mmsg-O.err" // Instead of the output if mmsg failed
+)
+
+// errReplaying is returned by everything that would change the running
+// compositor
+var errReplaying = errors.New("replaying a capture, nothing was sent to MangoWC")
+
+// Replay makes the package read a capture made by Capture instead of the
+// hardware and mmsg
+func Replay(dir string) error {
+	if _, err := os.Stat(filepath.Join(dir, "sys", "class", "drm")); err != nil {
+		return fmt.Errorf("%s is not a capture: %w", dir, err)
+	}
+	replayDir = dir
+	drmRoot = filepath.Join(dir, "sys", "class", "drm")
+	debugRoot = filepath.Join(dir, "sys", "kernel", "debug", "dri")
+	return nil
+}
+
+// ReplayDir is the capture being replayed, empty when using the hardware
+func ReplayDir() string {
+	return replayDir
+}
+
+// mmsgOutputs returns what `mmsg -O` prints
+func mmsgOutputs(ctx context.Context) ([]byte, error) {
+	if replayDir == "" {
+		return exec.CommandContext(ctx, "mmsg", "-O").Output()
+	}
+	if data, err := os.ReadFile(filepath.Join(replayDir, mmsgOutputsFile)); err == nil {
+		return data, nil
+	}
+	msg, err := os.ReadFile(filepath.Join(replayDir, mmsgErrorFile))
+	if err != nil {
+		return nil, errors.New("the capture has no mmsg output")
+	}
+	return nil, fmt.Errorf("at capture time: %s", strings.TrimSpace(string(msg)))
+}
@@ -0,0 +1,20 @@
+package system
+
+// Source says where a piece of hardware information came from, so the UI
+// can tell real data from stand-ins
+type Source int
+
+const (
+	SourceLive     Source = iota // Reported by the running compositor
+	SourceSysfs                  // Read from the kernel, the compositor wasn't asked
+	SourceFallback               // Made up so there is something to work with
+)
+
+var sourceNames = []string{"live", "sysfs", "fallback"}
+
+func (s Source) String() string {
+	if s >= 0 && int(s) < len(sourceNames) {
+		return sourceNames[s]
+	}
+	return "?"
+}
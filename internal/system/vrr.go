@@ -0,0 +1,62 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// VRRInfo says whether an output can do adaptive sync and in which range
+type VRRInfo struct {
+	Capable          bool
+	Likely           bool    // Capable only by the monitor's own claim, the driver wasn't asked
+	MinRate, MaxRate float64 // 0 if unknown
+	Source           string  // Where the answer came from, for display
+}
+
+func (v VRRInfo) String() string {
+	switch {
+	case !v.Capable:
+		return "not supported"
+	case v.Likely && v.MaxRate > 0:
+		return fmt.Sprintf("likely, %.0f-%.0f Hz", v.MinRate, v.MaxRate)
+	case v.Likely:
+		return "likely"
+	case v.MaxRate > 0:
+		return fmt.Sprintf("%.0f-%.0f Hz", v.MinRate, v.MaxRate)
+	default:
+		return "supported"
+	}
+}
+
+// GetVRRInfo checks the driver's view first: amdgpu exposes the range it
+// will use in debugfs (readable by root only). Otherwise it falls back to
+// the EDID, which says what the monitor claims. The DRM vrr_capable
+// property itself is only available through the KMS API, not sysfs.
+func GetVRRInfo(output string) (VRRInfo, error) {
+	dir, err := findConnector(output)
+	if err != nil {
+		return VRRInfo{}, err
+	}
+
+	// card1-DP-1 -> /sys/kernel/debug/dri/1/DP-1/vrr_range
+	card := strings.TrimSuffix(filepath.Base(dir), "-"+output)
+	path := filepath.Join(debugRoot, strings.TrimPrefix(card, "card"), output, "vrr_range")
+	if data, err := os.ReadFile(path); err == nil {
+		var minV, maxV float64
+		if _, err := fmt.Sscanf(string(data), "Min: %g\nMax: %g", &minV, &maxV); err == nil {
+			return VRRInfo{Capable: maxV > 0, MinRate: minV, MaxRate: maxV, Source: "driver"}, nil
+		}
+	}
+
+	edid, err := GetEDID(output)
+	if err != nil {
+		return VRRInfo{}, err
+	}
+	info := VRRInfo{Capable: edid.AdaptiveSync, Likely: edid.AdaptiveSync, Source: "EDID"}
+	if edid.AdaptiveSync {
+		info.MinRate, info.MaxRate = edid.MinRate, edid.MaxRate
+	}
+	return info, nil
+}
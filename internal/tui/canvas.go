@@ -0,0 +1,122 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// cell is one character of the canvas with the index of its style
+type cell struct {
+	ch    rune
+	style int
+}
+
+// canvas is a character buffer where every cell carries a style. Styles are
+// registered once per render and cells refer to them by index, so large
+// terminals don't mean copying styles around, and String renders runs of
+// equally styled cells in one go.
+type canvas struct {
+	cells  [][]cell
+	styles []lipgloss.Style
+}
+
+func newCanvas(width, height int) *canvas {
+	c := &canvas{
+		cells:  make([][]cell, height),
+		styles: []lipgloss.Style{lipgloss.NewStyle()}, // 0 is unstyled
+	}
+	for y := range c.cells {
+		c.cells[y] = make([]cell, width)
+		for x := range c.cells[y] {
+			c.cells[y][x] = cell{ch: ' '}
+		}
+	}
+	return c
+}
+
+// addStyle registers a style and returns its index for drawing
+func (c *canvas) addStyle(s lipgloss.Style) int {
+	c.styles = append(c.styles, s)
+	return len(c.styles) - 1
+}
+
+func (c *canvas) set(x, y int, ch rune, style int) {
+	if y < 0 || y >= len(c.cells) || x < 0 || x >= len(c.cells[y]) {
+		return
+	}
+	c.cells[y][x] = cell{ch: ch, style: style}
+}
+
+// fill paints the area with blanks in the given style
+func (c *canvas) fill(x1, y1, x2, y2, style int) {
+	for y := y1; y <= y2; y++ {
+		for x := x1; x <= x2; x++ {
+			c.set(x, y, ' ', style)
+		}
+	}
+}
+
+func (c *canvas) drawBox(x1, y1, x2, y2 int, box boxRunes, style int) {
+	for y := y1; y <= y2; y++ {
+		for x := x1; x <= x2; x++ {
+			var ch rune
+			if y == y1 && x == x1 {
+				ch = box.topLeft
+			} else if y == y1 && x == x2 {
+				ch = box.topRight
+			} else if y == y2 && x == x1 {
+				ch = box.bottomLeft
+			} else if y == y2 && x == x2 {
+				ch = box.bottomRight
+			} else if y == y1 || y == y2 {
+				ch = box.horizontal
+			} else if x == x1 || x == x2 {
+				ch = box.vertical
+			} else {
+				continue // Interior is filled separately
+			}
+			c.set(x, y, ch, style)
+		}
+	}
+}
+
+func (c *canvas) drawText(x, y, maxX int, text string, style int) {
+	for i, r := range []rune(text) {
+		if x+i > maxX {
+			break
+		}
+		c.set(x+i, y, r, style)
+	}
+}
+
+func (c *canvas) String() string {
+	var b strings.Builder
+	var run strings.Builder
+	for _, row := range c.cells {
+		for x := 0; x < len(row); {
+			style := row[x].style
+			run.Reset()
+			for ; x < len(row) && row[x].style == style; x++ {
+				run.WriteRune(row[x].ch)
+			}
+			if style == 0 {
+				b.WriteString(run.String())
+			} else {
+				b.WriteString(c.styles[style].Render(run.String()))
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// canFillBackground reports whether the terminal has enough colours for
+// filled monitor interiors. With 16 colours the fills degrade to garish
+// bright blocks, so only borders and text are coloured there; without
+// colour lipgloss drops the colours by itself.
+func canFillBackground() bool {
+	p := lipgloss.ColorProfile()
+	return p == termenv.TrueColor || p == termenv.ANSI256
+}
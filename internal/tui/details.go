@@ -0,0 +1,146 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"mangomon/config"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	detailsWidth     = 36  // Side panel width including the border
+	detailsMinWidth  = 100 // Narrower terminals get the panel below the grid
+	detailsMaxHeight = 9   // Rows of the bottom panel including the border
+)
+
+var (
+	detailsBox   = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("244")).Padding(0, 1)
+	detailsKey   = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	detailsTitle = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+)
+
+// detailsBeside reports whether the details panel goes to the right of the
+// grid rather than below it
+func (m Model) detailsBeside() bool {
+	return m.width >= detailsMinWidth
+}
+
+// detailsFields describes the selected monitor as label/value pairs
+func (m Model) detailsFields() (string, [][2]string) {
+	id := m.grid.SelectedID
+	r, ok := m.rules[id]
+	if !ok {
+		return "No monitor selected", nil
+	}
+
+	var fields [][2]string
+	add := func(k, format string, args ...any) {
+		fields = append(fields, [2]string{k, fmt.Sprintf(format, args...)})
+	}
+
+	if edid, ok := m.edids[id]; ok {
+		model := edid.Name
+		if model == "" {
+			model = fmt.Sprintf("%04X", edid.ProductCode)
+		}
+		add("Make", "%s %s", edid.Manufacturer, model)
+		if edid.Serial != "" {
+			add("Serial", "%s", edid.Serial)
+		} else if edid.SerialNumber != 0 {
+			add("Serial", "%d", edid.SerialNumber)
+		}
+		if edid.WidthMM > 0 && edid.HeightMM > 0 {
+			inches := math.Hypot(float64(edid.WidthMM), float64(edid.HeightMM)) / 25.4
+			add("Size", "%dx%d mm (%.1f\")", edid.WidthMM, edid.HeightMM, inches)
+		}
+		if p := edid.Preferred; p != nil {
+			add("Native", "%dx%d@%.2fHz", p.Width, p.Height, p.Rate)
+		}
+	} else {
+		add("Make", "unknown (no EDID)")
+	}
+
+	add("Output", "%s", m.outputSource(id))
+	mode := fmt.Sprintf("%dx%d@%gHz", r.Width, r.Height, math.Round(r.RefreshRate*1000)/1000)
+	if _, ok := m.guessed[id]; ok {
+		mode += " (unverified)"
+	}
+	add("Mode", "%s", mode)
+	w, h := r.LogicalSize()
+	add("Scale", "%g → %dx%d", math.Round(r.Scale*10000)/10000, w, h)
+	if ppi := m.edids[id].PPI(r.Width, r.Height); ppi > 0 {
+		add("Density", "%.0f ppi, %.0f dpi scaled", ppi, ppi/r.Scale)
+	}
+	add("Transform", "%s (rr:%d)", transformLabel(r.Transform), r.Transform)
+
+	vrr := "off"
+	if r.VariableRefreshRate == config.VRROn {
+		vrr = "on"
+	}
+	if info, ok := m.vrr[id]; ok {
+		vrr += ", " + info.String()
+	}
+	add("VRR", "%s", vrr)
+
+	add("Position", "%d,%d", r.X, r.Y)
+	if r.Anchor.IsSet() {
+		add("Anchor", "%s", r.Anchor)
+	}
+	if others := m.grid.MirroredWith(id); len(others) > 0 {
+		add("Mirrors", "%s", strings.Join(others, ", "))
+	}
+
+	if line, ok := m.parser.RuleLines[id]; ok {
+		add("Config", "%s:%d", m.parser.FilePath, line)
+	} else {
+		add("Config", "not saved yet")
+	}
+	return id, fields
+}
+
+// detailsPanel renders the panel for the given outer width. Beside the grid
+// it lists one field per line; below it, fields flow into columns.
+func (m Model) detailsPanel(width int) string {
+	title, fields := m.detailsFields()
+
+	var lines []string
+	if m.detailsBeside() {
+		for _, f := range fields {
+			lines = append(lines, detailsKey.Render(fmt.Sprintf("%-9s", f[0]))+" "+f[1])
+		}
+	} else {
+		// Fill columns top to bottom, sharing the width so that long values
+		// are shortened rather than whole columns cut off
+		const gap = 3
+		rows := detailsMaxHeight - detailsBox.GetVerticalFrameSize() - 1
+		n := (len(fields) + rows - 1) / rows
+		colWidth := (width-detailsBox.GetHorizontalFrameSize()+gap)/max(n, 1) - gap
+		var cols []string
+		for i := 0; i < len(fields); i += rows {
+			var col []string
+			for _, f := range fields[i:min(i+rows, len(fields))] {
+				col = append(col, detailsKey.Render(fmt.Sprintf("%-9s", f[0]))+" "+shorten(f[1], colWidth-10))
+			}
+			cols = append(cols, strings.Join(col, "\n"), strings.Repeat(" ", gap))
+		}
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, cols...))
+	}
+
+	body := detailsTitle.Render(title) + "\n" + strings.Join(lines, "\n")
+	return detailsBox.Width(width - detailsBox.GetHorizontalBorderSize()).MaxWidth(width).Render(body)
+}
+
+// shorten cuts a value to n cells, marking the cut with an ellipsis
+func shorten(s string, n int) string {
+	if lipgloss.Width(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > max(n, 1) {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"mangomon/config"
+	"mangomon/internal/system"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -33,20 +34,52 @@ var (
 				Foreground(lipgloss.Color("205"))
 )
 
+// monitorPalette gives each monitor its own colour: a foreground for its
+// border and text, and a dark background for its interior
+var monitorPalette = []struct{ fg, bg lipgloss.Color }{
+	{"39", "17"},
+	{"42", "22"},
+	{"141", "54"},
+	{"81", "23"},
+	{"220", "58"},
+	{"204", "52"},
+	{"111", "18"},
+	{"172", "94"},
+}
+
 type GridModel struct {
 	Rules         *map[string]config.MonitorRule
 	SelectedID    string
+	Marked        map[string]bool // Multi-selection for align/distribute
+	Disabled      map[string]bool // Turned off by the presentation switcher
+	Fabricated    map[string]bool // Example outputs, not found on this machine
 	GridSize      int
+	Zoom          float64 // 1 fits the whole layout
+	PanX, PanY    int     // Offset of the view center from the layout center
 	Width, Height int
+	EDIDs         map[string]system.EDID    // For physical density labels
+	VRR           map[string]system.VRRInfo // For adaptive sync labels
+
+	// Mouse drag state. The viewport is frozen while dragging, otherwise it
+	// would recenter under the cursor as the layout bounds change.
+	dragging             bool
+	dragView             viewport
+	dragStartX           int // World coordinates where the drag started
+	dragStartY           int
+	dragOrigX, dragOrigY int // Position of the monitor when the drag started
 }
 
 func NewGridModel(rules *map[string]config.MonitorRule) GridModel {
 	return GridModel{
 		Rules:    rules,
+		Marked:   make(map[string]bool),
+		Disabled: make(map[string]bool),
 		GridSize: 1,
+		Zoom:     1,
 	}
 }
 
+// Bounds of the layout in logical coordinates
 func (g GridModel) Bounds() (minX, minY, maxX, maxY int) {
 	minX, minY = math.MaxInt, math.MaxInt
 	maxX, maxY = math.MinInt, math.MinInt
@@ -56,23 +89,50 @@ func (g GridModel) Bounds() (minX, minY, maxX, maxY int) {
 	}
 
 	for _, r := range *g.Rules {
-		if r.X < minX {
-			minX = r.X
+		rect := r.Rect()
+		if rect.X < minX {
+			minX = rect.X
 		}
-		if r.Y < minY {
-			minY = r.Y
+		if rect.Y < minY {
+			minY = rect.Y
 		}
-		if r.X+r.Width > maxX {
-			maxX = r.X + r.Width
+		if rect.Right() > maxX {
+			maxX = rect.Right()
 		}
-		if r.Y+r.Height > maxY {
-			maxY = r.Y + r.Height
+		if rect.Bottom() > maxY {
+			maxY = rect.Bottom()
 		}
 	}
 	return
 }
 
-func (g GridModel) Render(termWidth, termHeight int) string {
+// viewport maps world (layout) coordinates to cells of the canvas
+type viewport struct {
+	minX, minY       int
+	scaleX, scaleY   float64
+	offsetX, offsetY int
+	width, height    int // Canvas size in cells
+}
+
+func (v viewport) worldToTerm(wx, wy int) (int, int) {
+	tx := v.offsetX + int(float64(wx-v.minX)*v.scaleX)
+	ty := v.offsetY + int(float64(wy-v.minY)*v.scaleY)
+	return tx, ty
+}
+
+func (v viewport) termToWorld(tx, ty int) (int, int) {
+	wx := v.minX + int(float64(tx-v.offsetX)/v.scaleX)
+	wy := v.minY + int(float64(ty-v.offsetY)/v.scaleY)
+	return wx, wy
+}
+
+// viewport computes the mapping for a canvas of the given terminal size.
+// The canvas starts one line below the top, under the header.
+func (g GridModel) viewport(termWidth, termHeight int) viewport {
+	if g.dragging && g.dragView.width == termWidth {
+		return g.dragView
+	}
+
 	minX, minY, maxX, maxY := g.Bounds()
 	totalW := maxX - minX
 	totalH := maxY - minY
@@ -84,32 +144,23 @@ func (g GridModel) Render(termWidth, termHeight int) string {
 		totalH = 1080
 	}
 
-	paddingX := 3000
-	paddingY := 3000
-
-	viewMinX := minX - paddingX/2
-	viewMinY := minY - paddingY/2
-	viewW := totalW + paddingX
-	viewH := totalH + paddingY
+	padding := layoutPadding(totalW, totalH)
 
-	header := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("241")).
-		Padding(0, 1).
-		Render(fmt.Sprintf("Grid: %d px", g.GridSize))
+	zoom := g.Zoom
+	if zoom <= 0 {
+		zoom = 1
+	}
+	viewW := int(float64(totalW+padding) / zoom)
+	viewH := int(float64(totalH+padding) / zoom)
+	centerX := minX + totalW/2 + g.PanX
+	centerY := minY + totalH/2 + g.PanY
 
-	renderHeight := termHeight - 2
+	// Header and legend take a line each
+	renderHeight := termHeight - 3
 	if renderHeight < 10 {
 		renderHeight = 10
 	}
 
-	desktop := make([][]rune, renderHeight)
-	for i := range desktop {
-		desktop[i] = make([]rune, termWidth)
-		for j := range desktop[i] {
-			desktop[i][j] = ' '
-		}
-	}
-
 	scaleX := float64(termWidth) / float64(viewW)
 	scaleY := float64(renderHeight) / float64(viewH)
 
@@ -120,15 +171,60 @@ func (g GridModel) Render(termWidth, termHeight int) string {
 		scaleY = scaleX / termAspect
 	}
 
-	offsetX := (termWidth - int(float64(viewW)*scaleX)) / 2
-	offsetY := (renderHeight - int(float64(viewH)*scaleY)) / 2
+	return viewport{
+		minX:    centerX - viewW/2,
+		minY:    centerY - viewH/2,
+		scaleX:  scaleX,
+		scaleY:  scaleY,
+		offsetX: (termWidth - int(float64(viewW)*scaleX)) / 2,
+		offsetY: (renderHeight - int(float64(viewH)*scaleY)) / 2,
+		width:   termWidth,
+		height:  renderHeight,
+	}
+}
+
+// layoutPadding is the room left around the layout at zoom 1, so monitors
+// can be moved outwards
+func layoutPadding(totalW, totalH int) int {
+	return max(max(totalW, totalH)/4, 400)
+}
 
-	worldToTerm := func(wx, wy int) (int, int) {
-		tx := offsetX + int(float64(wx-viewMinX)*scaleX)
-		ty := offsetY + int(float64(wy-viewMinY)*scaleY)
-		return tx, ty
+// boxRect is the cell rectangle the monitor is drawn in. visible is false
+// when the monitor is entirely outside the canvas.
+func (g GridModel) boxRect(v viewport, r config.MonitorRule) (x1, y1, x2, y2 int, visible bool) {
+	rect := r.Rect()
+	x1, y1 = v.worldToTerm(rect.X, rect.Y)
+	x2, y2 = v.worldToTerm(rect.Right(), rect.Bottom())
+
+	if x2-x1 < 6 {
+		x2 = x1 + 6
+	}
+	if y2-y1 < 4 {
+		y2 = y1 + 4
 	}
 
+	if x2 < 0 || y2 < 0 || x1 >= v.width || y1 >= v.height {
+		return 0, 0, 0, 0, false
+	}
+
+	if x1 < 0 {
+		x1 = 0
+	}
+	if y1 < 0 {
+		y1 = 0
+	}
+	if x2 >= v.width {
+		x2 = v.width - 1
+	}
+	if y2 >= v.height {
+		y2 = v.height - 1
+	}
+	return x1, y1, x2, y2, true
+}
+
+// drawOrder returns the rule IDs in the order they are drawn, the selected
+// monitor last so it ends up on top
+func (g GridModel) drawOrder() []string {
 	var ids []string
 	for id := range *g.Rules {
 		ids = append(ids, id)
@@ -142,69 +238,191 @@ func (g GridModel) Render(termWidth, termHeight int) string {
 		}
 		return ids[i] < ids[j]
 	})
+	return ids
+}
 
-	for _, id := range ids {
-		r := (*g.Rules)[id]
+func (g GridModel) Render(termWidth, termHeight int) string {
+	v := g.viewport(termWidth, termHeight)
 
-		x1, y1 := worldToTerm(r.X, r.Y)
-		x2, y2 := worldToTerm(r.X+r.Width, r.Y+r.Height)
+	headerText := fmt.Sprintf("Grid: %d px  Zoom: %.0f%%  %s", g.GridSize, g.Zoom*100, v.ruler())
+	header := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Padding(0, 1).
+		Render(headerText)
 
-		if x2-x1 < 6 {
-			x2 = x1 + 6
-		}
-		if y2-y1 < 4 {
-			y2 = y1 + 4
-		}
+	desktop := newCanvas(termWidth, v.height)
+	fillBg := canFillBackground()
+	colors := g.colors()
 
-		if x1 < 0 {
-			x1 = 0
-		}
-		if y1 < 0 {
-			y1 = 0
+	for _, id := range g.drawOrder() {
+		r := (*g.Rules)[id]
+		x1, y1, x2, y2, visible := g.boxRect(v, r)
+		if !visible {
+			continue
 		}
-		if x2 >= termWidth {
-			x2 = termWidth - 1
+		color := colors[id]
+
+		boxStyle := monitorBoxInactive
+		isActive := !g.Disabled[id]
+		if !isActive {
+			color.fg = "244" // Same grey as monitorBoxInactive
 		}
-		if y2 >= renderHeight {
-			y2 = renderHeight - 1
+		if id == g.SelectedID {
+			boxStyle = monitorBoxSelected
+		} else if g.isMirrored(id) {
+			boxStyle = monitorBoxMirror
+		} else if isActive {
+			boxStyle = monitorBoxActive.BorderForeground(color.fg)
 		}
 
-		style := monitorBoxInactive
-		isActive := true // TODO: check if enabled
+		textStyle := lipgloss.NewStyle().Foreground(color.fg)
+		borderStyle := lipgloss.NewStyle().Foreground(boxStyle.GetBorderTopForeground())
 		if id == g.SelectedID {
-			style = monitorBoxSelected
-		} else if isActive {
-			style = monitorBoxActive
+			borderStyle = borderStyle.Bold(true)
 		}
+		if fillBg {
+			textStyle = textStyle.Background(color.bg)
+			borderStyle = borderStyle.Background(color.bg)
+			desktop.fill(x1+1, y1+1, x2-1, y2-1, desktop.addStyle(textStyle))
+		}
+		labelStyle := desktop.addStyle(textStyle)
+		nameStyle := desktop.addStyle(textStyle.Bold(true))
 
-		box := getBoxRunes(style)
-		drawBox(desktop, x1, y1, x2, y2, box)
+		box := getBoxRunes(boxStyle)
+		desktop.drawBox(x1, y1, x2, y2, box, desktop.addStyle(borderStyle))
+		if r.Transform != 0 {
+			drawOrientation(desktop, x1, y1, x2, y2, r.Transform, desktop.addStyle(borderStyle.Bold(true)))
+		}
 
 		status := "[ON]"
 		if !isActive {
 			status = "[OFF]"
 		}
+		if g.Fabricated[id] {
+			status = "[EXAMPLE]"
+		}
 		nameLabel := fmt.Sprintf("%s %s", id, status)
-		drawText(desktop, x1+1, y1+1, x2-1, nameLabel)
+		if g.Marked[id] {
+			nameLabel = "● " + nameLabel
+		}
+		desktop.drawText(x1+1, y1+1, x2-1, nameLabel, nameStyle)
 
 		// 2. Resolution
 		resLabel := fmt.Sprintf("%dx%d@%.0fHz", r.Width, r.Height, r.RefreshRate)
-		drawText(desktop, x1+1, y1+2, x2-1, resLabel)
+		desktop.drawText(x1+1, y1+2, x2-1, resLabel, labelStyle)
 
-		// 3. Scale
+		// 3. Scale and density
+		var extra []string
 		if r.Scale < 0.99 || r.Scale > 1.01 {
-			scaleLabel := fmt.Sprintf("x%.2f", r.Scale)
-			drawText(desktop, x1+1, y1+3, x2-1, scaleLabel)
+			extra = append(extra, fmt.Sprintf("x%.2f", r.Scale))
+		}
+		if r.Transform != 0 {
+			extra = append(extra, transformLabel(r.Transform))
+		}
+		if r.VariableRefreshRate == config.VRROn {
+			extra = append(extra, g.vrrLabel(id))
+		}
+		if ppi := g.EDIDs[id].PPI(r.Width, r.Height); ppi > 0 {
+			extra = append(extra, fmt.Sprintf("%.0fppi", ppi))
+		}
+		if len(extra) > 0 {
+			desktop.drawText(x1+1, y1+3, x2-1, strings.Join(extra, " "), labelStyle)
 		}
 	}
 
-	// Convert to string
-	var access strings.Builder
-	for _, row := range desktop {
-		access.WriteString(string(row) + "\n")
+	return header + "\n" + desktop.String() + g.legend(colors, termWidth)
+}
+
+// drawOrientation marks the middle of the edge where the panel's own top
+// ended up, so rotated and flipped monitors can be told apart
+func drawOrientation(c *canvas, x1, y1, x2, y2, transform, style int) {
+	switch config.PanelTop(transform) {
+	case config.EdgeTop:
+		c.set((x1+x2)/2, y1, '▲', style)
+	case config.EdgeRight:
+		c.set(x2, (y1+y2)/2, '▶', style)
+	case config.EdgeBottom:
+		c.set((x1+x2)/2, y2, '▼', style)
+	case config.EdgeLeft:
+		c.set(x1, (y1+y2)/2, '◀', style)
+	}
+}
+
+// vrrLabel shows the range VRR works in, or a ! if the monitor doesn't
+// support it
+func (g GridModel) vrrLabel(id string) string {
+	info, ok := g.VRR[id]
+	switch {
+	case !ok:
+		return "VRR"
+	case !info.Capable:
+		return "VRR!"
+	case info.MaxRate > 0:
+		return fmt.Sprintf("VRR %.0f-%.0f", info.MinRate, info.MaxRate)
+	}
+	return "VRR"
+}
+
+// transformLabel is the short form of a transform for box labels
+func transformLabel(transform int) string {
+	s := fmt.Sprintf("%d°", (transform%4)*90)
+	if config.Flipped(transform) {
+		s = "flip " + s
+	}
+	return s
+}
+
+// colors assigns palette entries by name, so a monitor keeps its colour no
+// matter which one is selected
+func (g GridModel) colors() map[string]struct{ fg, bg lipgloss.Color } {
+	var ids []string
+	for id := range *g.Rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	colors := make(map[string]struct{ fg, bg lipgloss.Color }, len(ids))
+	for i, id := range ids {
+		colors[id] = monitorPalette[i%len(monitorPalette)]
+	}
+	return colors
+}
+
+// legend lists the monitors in their colours, numbered in spatial order
+// for the 1-9 keys
+func (g GridModel) legend(colors map[string]struct{ fg, bg lipgloss.Color }, termWidth int) string {
+	var items []string
+	for i, id := range g.SpatialOrder() {
+		style := lipgloss.NewStyle().Foreground(colors[id].fg)
+		name := id
+		if id == g.SelectedID {
+			name = lipgloss.NewStyle().Bold(true).Render(id)
+		}
+		item := fmt.Sprintf("%d %s %s", i+1, style.Render("■"), name)
+		if g.isMirrored(id) {
+			item += lipgloss.NewStyle().Foreground(monitorBoxMirror.GetForeground()).Render(" (mirror)")
+		}
+		items = append(items, item)
 	}
+	return lipgloss.NewStyle().MaxWidth(termWidth).Padding(0, 1).Render(strings.Join(items, "  "))
+}
 
-	return header + "\n" + access.String()
+// isMirrored reports whether another monitor covers exactly the same area
+func (g GridModel) isMirrored(id string) bool {
+	return len(g.MirroredWith(id)) > 0
+}
+
+// MirroredWith lists the other monitors covering exactly the same area
+func (g GridModel) MirroredWith(id string) []string {
+	rect := (*g.Rules)[id].Rect()
+	var ids []string
+	for other, r := range *g.Rules {
+		if other != id && r.Rect() == rect {
+			ids = append(ids, other)
+		}
+	}
+	sort.Strings(ids)
+	return ids
 }
 
 // Drawing Helpers
@@ -234,60 +452,6 @@ func getBoxRunes(style lipgloss.Style) boxRunes {
 	}
 }
 
-func drawBox(desktop [][]rune, x1, y1, x2, y2 int, box boxRunes) {
-	for y := y1; y <= y2; y++ {
-		for x := x1; x <= x2; x++ {
-			var ch rune
-			if y == y1 && x == x1 {
-				ch = box.topLeft
-			} else if y == y1 && x == x2 {
-				ch = box.topRight
-			} else if y == y2 && x == x1 {
-				ch = box.bottomLeft
-			} else if y == y2 && x == x2 {
-				ch = box.bottomRight
-			} else if y == y1 || y == y2 {
-				ch = box.horizontal
-			} else if x == x1 || x == x2 {
-				ch = box.vertical
-			} else {
-				continue // Don't fill center yet
-			}
-			desktop[y][x] = ch
-		}
-	}
-}
-
-func drawText(desktop [][]rune, x, y, maxX int, text string) {
-	if y >= len(desktop) || y < 0 {
-		return
-	}
-	runes := []rune(text)
-	for i, r := range runes {
-		if x+i >= len(desktop[0]) || x+i > maxX {
-			break
-		}
-		desktop[y][x+i] = r
-	}
-}
-
-func splitLines(s string) []string {
-	var lines []string
-	cur := ""
-	for _, r := range s {
-		if r == '\n' {
-			lines = append(lines, cur)
-			cur = ""
-		} else {
-			cur += string(r)
-		}
-	}
-	if cur != "" {
-		lines = append(lines, cur)
-	}
-	return lines
-}
-
 func (g *GridModel) MoveSelected(dx, dy int) {
 	if rule, ok := (*g.Rules)[g.SelectedID]; ok {
 		stepX := dx * g.GridSize
@@ -295,6 +459,7 @@ func (g *GridModel) MoveSelected(dx, dy int) {
 
 		rule.X += stepX
 		rule.Y += stepY
+		rule.Anchor = config.Anchor{} // Placed by hand now
 		(*g.Rules)[g.SelectedID] = rule
 	}
 }
@@ -313,3 +478,289 @@ func (g *GridModel) CycleGrid() {
 		g.GridSize = 1
 	}
 }
+
+// MonitorAt returns the monitor drawn at the given canvas cell, or "".
+// Hit testing goes in reverse draw order so the topmost box wins.
+func (g GridModel) MonitorAt(termWidth, termHeight, col, row int) string {
+	v := g.viewport(termWidth, termHeight)
+	ids := g.drawOrder()
+	for i := len(ids) - 1; i >= 0; i-- {
+		x1, y1, x2, y2, visible := g.boxRect(v, (*g.Rules)[ids[i]])
+		if visible && col >= x1 && col <= x2 && row >= y1 && row <= y2 {
+			return ids[i]
+		}
+	}
+	return ""
+}
+
+// StartDrag begins moving the selected monitor with the mouse
+func (g *GridModel) StartDrag(termWidth, termHeight, col, row int) {
+	rule, ok := (*g.Rules)[g.SelectedID]
+	if !ok {
+		return
+	}
+	v := g.viewport(termWidth, termHeight)
+	g.dragging = true
+	g.dragView = v
+	g.dragStartX, g.dragStartY = v.termToWorld(col, row)
+	g.dragOrigX, g.dragOrigY = rule.X, rule.Y
+}
+
+// Drag moves the selected monitor to follow the mouse. The position is
+// snapped to the grid and then to the edges of the other monitors when they
+// are within a cell.
+func (g *GridModel) Drag(termWidth, termHeight, col, row int) {
+	rule, ok := (*g.Rules)[g.SelectedID]
+	if !g.dragging || !ok {
+		return
+	}
+	v := g.viewport(termWidth, termHeight)
+	wx, wy := v.termToWorld(col, row)
+
+	x := snapToGrid(g.dragOrigX+wx-g.dragStartX, g.GridSize)
+	y := snapToGrid(g.dragOrigY+wy-g.dragStartY, g.GridSize)
+	rule.X, rule.Y = g.snapToEdges(rule, x, y, int(1/v.scaleX), int(1/v.scaleY))
+	rule.Anchor = config.Anchor{} // Placed by hand now
+	(*g.Rules)[g.SelectedID] = rule
+}
+
+// EndDrag stops a mouse drag
+func (g *GridModel) EndDrag() {
+	g.dragging = false
+}
+
+// Dragging reports whether a mouse drag is in progress
+func (g GridModel) Dragging() bool {
+	return g.dragging
+}
+
+func snapToGrid(v, size int) int {
+	if size <= 1 {
+		return v
+	}
+	return int(math.Round(float64(v)/float64(size))) * size
+}
+
+// snapToEdges moves x/y onto the nearest edge of another monitor if one is
+// closer than the threshold
+func (g GridModel) snapToEdges(rule config.MonitorRule, x, y, thresholdX, thresholdY int) (int, int) {
+	w, h := rule.LogicalSize()
+	bestX, bestY := thresholdX+1, thresholdY+1
+	snapX, snapY := x, y
+
+	for id, other := range *g.Rules {
+		if id == rule.ID {
+			continue
+		}
+		o := other.Rect()
+		for _, edge := range []int{o.X, o.Right()} {
+			for _, cand := range []int{edge, edge - w} {
+				if d := abs(cand - x); d < bestX {
+					bestX, snapX = d, cand
+				}
+			}
+		}
+		for _, edge := range []int{o.Y, o.Bottom()} {
+			for _, cand := range []int{edge, edge - h} {
+				if d := abs(cand - y); d < bestY {
+					bestY, snapY = d, cand
+				}
+			}
+		}
+	}
+	return snapX, snapY
+}
+
+// ZoomBy multiplies the zoom level, keeping it within sane limits
+func (g *GridModel) ZoomBy(factor float64) {
+	g.Zoom *= factor
+	if g.Zoom < 0.25 {
+		g.Zoom = 0.25
+	}
+	if g.Zoom > 64 {
+		g.Zoom = 64
+	}
+}
+
+// ZoomAt zooms while keeping the world point under the given cell in place,
+// which is what scrolling over the canvas should do
+func (g *GridModel) ZoomAt(termWidth, termHeight, col, row int, factor float64) {
+	beforeX, beforeY := g.viewport(termWidth, termHeight).termToWorld(col, row)
+	g.ZoomBy(factor)
+	afterX, afterY := g.viewport(termWidth, termHeight).termToWorld(col, row)
+	g.PanX += beforeX - afterX
+	g.PanY += beforeY - afterY
+}
+
+// Pan moves the view by a fraction of what is currently visible
+func (g *GridModel) Pan(termWidth, termHeight int, fx, fy float64) {
+	v := g.viewport(termWidth, termHeight)
+	g.PanX += int(fx * float64(v.width) / v.scaleX)
+	g.PanY += int(fy * float64(v.height) / v.scaleY)
+}
+
+// FitAll resets the view so the whole layout is visible
+func (g *GridModel) FitAll() {
+	g.Zoom = 1
+	g.PanX, g.PanY = 0, 0
+}
+
+// FocusSelected centers the view on the selected monitor and zooms so it
+// takes up about half of the canvas
+func (g *GridModel) FocusSelected() {
+	rule, ok := (*g.Rules)[g.SelectedID]
+	if !ok {
+		return
+	}
+	minX, minY, maxX, maxY := g.Bounds()
+	r := rule.Rect()
+	if r.W <= 0 || r.H <= 0 {
+		return
+	}
+
+	g.Zoom = 1
+	g.PanX = r.X + r.W/2 - (minX + (maxX-minX)/2)
+	g.PanY = r.Y + r.H/2 - (minY + (maxY-minY)/2)
+
+	// Zoom 1 shows the layout plus padding, see viewport
+	totalW, totalH := maxX-minX, maxY-minY
+	padding := layoutPadding(totalW, totalH)
+	g.ZoomBy(math.Min(float64(totalW+padding)/float64(2*r.W), float64(totalH+padding)/float64(2*r.H)))
+}
+
+// ruler is a scale bar of a round number of pixels, about ten cells wide
+func (v viewport) ruler() string {
+	if v.scaleX <= 0 {
+		return ""
+	}
+	target := 10 / v.scaleX
+	step := math.Pow(10, math.Floor(math.Log10(target)))
+	px := step
+	for _, m := range []float64{2, 5, 10} {
+		if step*m <= target {
+			px = step * m
+		}
+	}
+	cells := int(px * v.scaleX)
+	if cells < 2 {
+		cells = 2
+	}
+	return fmt.Sprintf("├%s┤ %.0f px", strings.Repeat("─", cells-2), px)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// SpatialOrder lists the monitors left to right, then top to bottom
+func (g GridModel) SpatialOrder() []string {
+	var ids []string
+	for id := range *g.Rules {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := (*g.Rules)[ids[i]], (*g.Rules)[ids[j]]
+		if a.X != b.X {
+			return a.X < b.X
+		}
+		if a.Y != b.Y {
+			return a.Y < b.Y
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// CycleSelection moves the selection by delta in spatial order, wrapping
+// around
+func (g *GridModel) CycleSelection(delta int) {
+	ids := g.SpatialOrder()
+	if len(ids) == 0 {
+		return
+	}
+	for i, id := range ids {
+		if id == g.SelectedID {
+			g.SelectedID = ids[((i+delta)%len(ids)+len(ids))%len(ids)]
+			return
+		}
+	}
+	g.SelectedID = ids[0]
+}
+
+// SelectNumber selects the nth monitor (1-based) in spatial order
+func (g *GridModel) SelectNumber(n int) bool {
+	ids := g.SpatialOrder()
+	if n < 1 || n > len(ids) {
+		return false
+	}
+	g.SelectedID = ids[n-1]
+	return true
+}
+
+// SelectDirection selects the nearest monitor whose center lies in the
+// direction (dx, dy) from the selected one. Monitors straight ahead win
+// over closer ones off to the side.
+func (g *GridModel) SelectDirection(dx, dy int) bool {
+	cur, ok := (*g.Rules)[g.SelectedID]
+	if !ok {
+		return false
+	}
+	center := func(r config.Rect) (int, int) { return r.X + r.W/2, r.Y + r.H/2 }
+	cx, cy := center(cur.Rect())
+
+	best, bestScore := "", math.MaxInt
+	for id, r := range *g.Rules {
+		if id == g.SelectedID {
+			continue
+		}
+		x, y := center(r.Rect())
+		ahead := (x-cx)*dx + (y-cy)*dy     // Distance along the direction
+		side := abs((x-cx)*dy + (y-cy)*dx) // Distance across it
+		if ahead <= 0 {
+			continue
+		}
+		if score := ahead + 2*side; score < bestScore || (score == bestScore && id < best) {
+			best, bestScore = id, score
+		}
+	}
+	if best == "" {
+		return false
+	}
+	g.SelectedID = best
+	return true
+}
+
+// ToggleMark adds or removes the selected monitor from the multi-selection
+func (g *GridModel) ToggleMark() {
+	if _, ok := (*g.Rules)[g.SelectedID]; !ok {
+		return
+	}
+	if g.Marked[g.SelectedID] {
+		delete(g.Marked, g.SelectedID)
+	} else {
+		g.Marked[g.SelectedID] = true
+	}
+}
+
+// ClearMarks empties the multi-selection
+func (g *GridModel) ClearMarks() {
+	g.Marked = make(map[string]bool)
+}
+
+// MarkedIDs returns the marked monitors plus the selected one, sorted
+func (g GridModel) MarkedIDs() []string {
+	var ids []string
+	for id := range g.Marked {
+		if _, ok := (*g.Rules)[id]; ok {
+			ids = append(ids, id)
+		}
+	}
+	if _, ok := (*g.Rules)[g.SelectedID]; ok && !g.Marked[g.SelectedID] {
+		ids = append(ids, g.SelectedID)
+	}
+	sort.Strings(ids)
+	return ids
+}
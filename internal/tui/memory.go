@@ -0,0 +1,140 @@
+package tui
+
+import (
+	"mangomon/config"
+	"mangomon/internal/state"
+	"mangomon/internal/system"
+)
+
+// rememberMonitors stores the settings of every connected monitor with an
+// EDID under its memory key, so they can be reused when it shows up without
+// a rule, e.g. on another machine or connector
+func (m Model) rememberMonitors() error {
+	appState, err := state.Load()
+	if err != nil {
+		return err
+	}
+	if appState.Monitors == nil {
+		appState.Monitors = make(map[string]state.MonitorMemory)
+	}
+
+	keys := memoryKeys(m.edids)
+	for name := range m.edids {
+		rule, ok := m.rules[name]
+		if !ok {
+			continue
+		}
+		rule.ID = ""
+		if rule.Anchor.IsSet() {
+			// Only keep anchors to monitors that can be recognised later
+			if target, ok := keys[rule.Anchor.Target]; ok {
+				rule.Anchor.Target = target
+			} else {
+				rule.Anchor = config.Anchor{}
+			}
+		}
+		appState.Monitors[keys[name]] = state.MonitorMemory{Rule: rule, Connector: name}
+	}
+	return state.Save(appState)
+}
+
+// memoryKey is what a monitor is remembered under: its EDID identity, plus
+// the connector for monitors that report no serial, since identical units
+// of such a model can't be told apart otherwise. Those are only recognised
+// on the connector they were saved on.
+func memoryKey(name string, edid system.EDID) string {
+	if edid.Serial == "" && edid.SerialNumber == 0 {
+		return edid.Identity() + "@" + name
+	}
+	return edid.Identity()
+}
+
+// memoryKeys maps connector names to memory keys
+func memoryKeys(edids map[string]system.EDID) map[string]string {
+	keys := make(map[string]string, len(edids))
+	for name, edid := range edids {
+		keys[name] = memoryKey(name, edid)
+	}
+	return keys
+}
+
+// rememberedRule returns the remembered settings for a connected monitor.
+// Its anchor still points at the target's memory key, see reattach.
+func rememberedRule(name string, edids map[string]system.EDID, memory map[string]state.MonitorMemory) (config.MonitorRule, bool) {
+	edid, ok := edids[name]
+	if !ok {
+		return config.MonitorRule{}, false
+	}
+	mem, ok := memory[memoryKey(name, edid)]
+	if !ok {
+		return config.MonitorRule{}, false
+	}
+	rule := mem.Rule
+	rule.ID = name
+	return rule, true
+}
+
+// reattach points a remembered rule's anchor at the connector the target
+// monitor is on now. It has to run once every rule exists, since the target
+// may have been added in the same probe. Without a usable anchor the monitor
+// goes to the right of the layout.
+func reattach(name string, edids map[string]system.EDID, rules map[string]config.MonitorRule) {
+	rule := rules[name]
+	if rule.Anchor.IsSet() {
+		target := rule.Anchor.Target
+		rule.Anchor.Target = ""
+		for other, key := range memoryKeys(edids) {
+			if _, ok := rules[other]; ok && other != name && key == target {
+				rule.Anchor.Target = other
+			}
+		}
+		if rule.Anchor.Target == "" {
+			rule.Anchor = config.Anchor{}
+		}
+	}
+	if !rule.Anchor.IsSet() {
+		placeRight(&rule, rules)
+	}
+	rules[name] = rule
+}
+
+// libraryRule builds a rule for a connected monitor from the model library,
+// starting from the default for settings the library entry leaves out
+func libraryRule(name string, edids map[string]system.EDID, lib config.Library, rules map[string]config.MonitorRule) (config.MonitorRule, bool) {
+	edid, ok := edids[name]
+	if !ok {
+		return config.MonitorRule{}, false
+	}
+	entry, ok := lib.Lookup(edid.Manufacturer, edid.Models(), edid.Diagonal())
+	if !ok {
+		return config.MonitorRule{}, false
+	}
+	rule := defaultRule(name, edids)
+	entry.Apply(&rule)
+	placeRight(&rule, rules)
+	return rule, true
+}
+
+// defaultRule is used for outputs nothing is known about: the native mode
+// from the EDID, or 1920x1080@60 without one
+func defaultRule(name string, edids map[string]system.EDID) config.MonitorRule {
+	rule := config.MonitorRule{
+		ID:    name,
+		Scale: 1.0,
+		Width: 1920, Height: 1080, RefreshRate: 60,
+	}
+	if p := edids[name].Preferred; p != nil {
+		rule.Width, rule.Height, rule.RefreshRate = p.Width, p.Height, p.Rate
+	}
+	return rule
+}
+
+// placeRight puts the rule to the right of the layout
+func placeRight(rule *config.MonitorRule, rules map[string]config.MonitorRule) {
+	rule.X, rule.Y = 0, 0
+	for id, r := range rules {
+		if id != rule.ID {
+			rule.X = max(rule.X, r.Rect().Right())
+		}
+	}
+}
@@ -2,12 +2,22 @@ package tui
 
 import (
 	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
 	"mangomon/config"
+	"mangomon/internal/convert"
+	"mangomon/internal/present"
 	"mangomon/internal/state"
 	"mangomon/internal/system"
 	"mangomon/internal/tui/tools"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 type modelState int
@@ -19,14 +29,37 @@ const (
 	stateMirror
 	stateTransform
 	stateVRR
+	stateCheck
+	stateExport
+	statePosition
+	stateAlign
+	statePresent
 )
 
 type Model struct {
 	outputs []system.Output
+	edids   map[string]system.EDID    // Outputs whose EDID could be read
+	vrr     map[string]system.VRRInfo // Same, for adaptive sync support
 	rules   map[string]config.MonitorRule
 	state   modelState
 	parser  *config.ConfigParser
 	err     error
+	status  string // One-off message shown in the footer
+
+	showDetails bool // Details panel for the selected monitor
+
+	// Hardware probing, see probe.go
+	probingOutputs bool
+	probingModes   string // Output whose modes are being read
+	probeErr       error  // Listing the outputs failed
+	modeCache      map[string][]system.Mode
+	hotplugStatus  string
+	spinner        spinner.Model
+	spinning       bool
+
+	guessed     map[string]string // Rules using settings the hardware didn't report, and why
+	confirmSave bool              // Save was pressed once despite guessed settings
+	confirmMode bool              // A presentation mode was chosen once despite guessed settings
 
 	// Grid state
 	grid GridModel
@@ -37,61 +70,57 @@ type Model struct {
 	mirrorPicker    tools.MirrorPickerModel
 	transformPicker tools.TransformPickerModel
 	vrrPicker       tools.VRRPickerModel
+	checkPanel      tools.CheckPanelModel
+	exportPicker    tools.ExportPickerModel
+	positionPicker  tools.PositionPickerModel
+	alignPicker     tools.AlignPickerModel
+	presentPicker   tools.PresentPickerModel
 
 	width, height int
 }
 
+// InitialModel shows the configured rules straight away, the outputs are
+// probed once the program runs
 func InitialModel(parser *config.ConfigParser) Model {
-	outputs, err := system.GetOutputs()
-	// Mock outputs if none found for testing
-	if len(outputs) == 0 {
-		// Fallback or just empty
-	}
-
 	rules, _ := parser.Parse()
 
-	// Ensure every output has a rule
-	for _, out := range outputs {
-		if _, ok := rules[out.Name]; !ok {
-			rules[out.Name] = config.MonitorRule{
-				ID:                  out.Name,
-				Scale:               1.0,
-				Transform:           0,
-				VariableRefreshRate: 0,
-				X:                   0, Y: 0,
-				Width: 1920, Height: 1080, RefreshRate: 60,
-			}
-		}
-	}
-
-	initialSelected := ""
-	if len(outputs) > 0 {
-		initialSelected = outputs[0].Name
-	}
+	// Positions in the file may predate a change to an anchor's target
+	config.Solve(rules)
 
 	grid := NewGridModel(&rules)
-	grid.SelectedID = initialSelected
 
-	// Load app state (GridSize only)
+	// Restore GridSize and viewport
 	if appState, err := state.Load(); err == nil {
 		grid.GridSize = appState.GridSize
 		if grid.GridSize == 0 {
 			grid.GridSize = 1
 		}
+		if appState.Zoom > 0 {
+			grid.Zoom = appState.Zoom
+		}
+		grid.PanX, grid.PanY = appState.PanX, appState.PanY
+		for _, id := range appState.Disabled {
+			grid.Disabled[id] = true
+		}
 	}
 
 	return Model{
-		outputs: outputs,
-		rules:   rules,
-		parser:  parser,
-		err:     err,
-		grid:    grid,
-		state:   stateGrid,
+		edids:          make(map[string]system.EDID),
+		vrr:            make(map[string]system.VRRInfo),
+		rules:          rules,
+		parser:         parser,
+		grid:           grid,
+		state:          stateGrid,
+		probingOutputs: true,
+		modeCache:      make(map[string][]system.Mode),
+		guessed:        make(map[string]string),
+		spinner:        spinner.New(spinner.WithSpinner(spinner.Dot)),
+		spinning:       true,
 	}
 }
 
 func (m Model) Init() tea.Cmd {
-	return nil
+	return tea.Batch(probeOutputs(), m.spinner.Tick, watchHotplug())
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -101,12 +130,45 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 		return m, nil
 
+	// Hardware probing
+	case outputsProbedMsg:
+		m.applyOutputs(msg)
+		return m, nil
+
+	case modesProbedMsg:
+		m.probingModes = ""
+		if msg.err == nil {
+			m.modeCache[msg.output] = msg.modes
+		}
+		// The user may have moved on while waiting
+		if m.state == stateGrid && m.grid.SelectedID == msg.output {
+			m.openModePicker(msg.modes, msg.err)
+		}
+		return m, nil
+
+	case hotplugMsg:
+		cmd := watchHotplug()
+		if msg.err == nil && !m.probingOutputs && msg.status != m.hotplugStatus {
+			cmd = tea.Batch(cmd, m.reprobe())
+		}
+		return m, cmd
+
+	case spinner.TickMsg:
+		if !m.busy() {
+			m.spinning = false
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
 	// Sub-model returns
 	case tools.ScaleSelectedMsg:
 		if rule, ok := m.rules[m.grid.SelectedID]; ok {
 			rule.Scale = msg.Scale
 			m.rules[m.grid.SelectedID] = rule
 		}
+		m.status = m.relayout()
 		m.state = stateGrid
 		m.grid.Rules = &m.rules // Refresh grid pointer just in case
 		return m, nil
@@ -121,7 +183,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			rule.Height = msg.Mode.Height
 			rule.RefreshRate = msg.Mode.Rate
 			m.rules[m.grid.SelectedID] = rule
+			if msg.Mode.Guessed {
+				m.guessed[rule.ID] = fmt.Sprintf("%s wasn't reported by the monitor", msg.Mode)
+			} else {
+				delete(m.guessed, rule.ID)
+			}
 		}
+		m.status = m.relayout()
 		m.state = stateGrid
 		m.grid.Rules = &m.rules
 		return m, nil
@@ -131,6 +199,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tools.MirrorSelectedMsg:
+		before := make(map[string]config.MonitorRule, len(m.rules))
+		for id, r := range m.rules {
+			before[id] = r
+		}
 		// Align position and resolution to target
 		if rule, ok := m.rules[m.grid.SelectedID]; ok {
 			if target, ok := m.rules[msg.TargetID]; ok {
@@ -138,10 +210,71 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				rule.Y = target.Y
 				rule.Width = target.Width
 				rule.Height = target.Height
+				rule.Anchor = config.Anchor{}
 			}
 			m.rules[m.grid.SelectedID] = rule
 		}
+		m.status = m.relayout()
+		m.state = stateGrid
+		// So that switching to extend undoes it
+		var names []string
+		for _, o := range m.outputs {
+			names = append(names, o.Name)
+		}
+		if err := present.Mirrored(before, names); err != nil {
+			m.err = err
+		}
+		return m, nil
+
+	case tools.PresentSelectedMsg:
+		m.state = stateGrid
+		confirmMode := m.confirmMode
+		m.confirmMode = false
+		var names []string
+		for _, o := range m.outputs {
+			if o.Fabricated() {
+				m.status = fmt.Sprintf("Can't switch while showing example outputs (%v). Ctrl+R to retry", m.probeErr)
+				return m, nil
+			}
+			names = append(names, o.Name)
+		}
+		// Switching saves the config, so it asks the same as S does
+		if warning := m.saveWarning(); warning != "" && !confirmMode {
+			m.confirmMode = true
+			m.status = fmt.Sprintf("Unverified settings (%s). Choose %s again to switch anyway", warning, msg.Mode)
+			return m, nil
+		}
+		rulesToSave, _ := m.savableRules()
+		switched := make(map[string]config.MonitorRule)
+		for _, r := range rulesToSave {
+			switched[r.ID] = r
+		}
+		res, err := present.Switch(m.parser, switched, names, msg.Mode)
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		for id, r := range switched {
+			m.rules[id] = r
+		}
+		if res.Already {
+			m.status = fmt.Sprintf("Already in %s mode, nothing changed", msg.Mode)
+			return m, nil
+		}
+		m.guessed = make(map[string]string)
+		m.grid.Disabled = make(map[string]bool)
+		for _, id := range res.Disabled {
+			m.grid.Disabled[id] = true
+		}
+		m.status = fmt.Sprintf("Switched to %s", msg.Mode)
+		if res.LiveErr != nil {
+			m.status += ": " + res.LiveErr.Error()
+		}
+		return m, nil
+
+	case tools.PresentCancelledMsg:
 		m.state = stateGrid
+		m.confirmMode = false
 		return m, nil
 
 	case tools.MirrorCancelledMsg:
@@ -153,6 +286,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			rule.Transform = msg.Transform
 			m.rules[m.grid.SelectedID] = rule
 		}
+		m.status = m.relayout()
 		m.state = stateGrid
 		m.grid.Rules = &m.rules
 		return m, nil
@@ -174,6 +308,66 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.state = stateGrid
 		return m, nil
 
+	case tools.CheckFixMsg:
+		// Fix rewrites the file as it is on disk. The rules in the editor
+		// are left alone so unsaved edits survive; S writes them over it.
+		n, err := m.parser.Fix()
+		m.checkPanel = tools.NewCheckPanel(m.parser.FilePath, m.diagnostics())
+		if err != nil {
+			m.checkPanel.Status = fmt.Sprintf("Error: %v", err)
+		} else {
+			m.checkPanel.Status = fmt.Sprintf("Fixed %d problem(s).", n)
+		}
+		return m, nil
+
+	case tools.CheckClosedMsg:
+		m.state = stateGrid
+		return m, nil
+
+	case tools.ExportSelectedMsg:
+		path, err := m.export(msg.Format)
+		if err != nil {
+			m.err = err
+		} else {
+			m.status = fmt.Sprintf("Exported %s layout to %s", msg.Format, path)
+		}
+		m.state = stateGrid
+		return m, nil
+
+	case tools.ExportCancelledMsg:
+		m.state = stateGrid
+		return m, nil
+
+	case tools.PositionSelectedMsg:
+		if rule, ok := m.rules[m.grid.SelectedID]; ok {
+			rule.X = msg.X
+			rule.Y = msg.Y
+			rule.Anchor = msg.Anchor
+			m.rules[m.grid.SelectedID] = rule
+		}
+		m.status = m.relayout()
+		m.state = stateGrid
+		return m, nil
+
+	case tools.PositionCancelledMsg:
+		m.state = stateGrid
+		return m, nil
+
+	case tools.AlignSelectedMsg:
+		for _, id := range m.grid.MarkedIDs() {
+			rule := m.rules[id]
+			rule.Anchor = config.Anchor{} // Arranged by hand now
+			m.rules[id] = rule
+		}
+		config.Arrange(m.rules, m.grid.MarkedIDs(), msg.Op)
+		m.status = m.relayout()
+		m.state = stateGrid
+		return m, nil
+
+	case tools.AlignCancelledMsg:
+		m.state = stateGrid
+		return m, nil
+
 	}
 
 	// Delegate based on state
@@ -200,35 +394,103 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		newModel, cmd := m.vrrPicker.Update(msg)
 		m.vrrPicker = newModel.(tools.VRRPickerModel)
 		return m, cmd
+	case stateCheck:
+		newModel, cmd := m.checkPanel.Update(msg)
+		m.checkPanel = newModel.(tools.CheckPanelModel)
+		return m, cmd
+	case stateExport:
+		newModel, cmd := m.exportPicker.Update(msg)
+		m.exportPicker = newModel.(tools.ExportPickerModel)
+		return m, cmd
+	case statePosition:
+		newModel, cmd := m.positionPicker.Update(msg)
+		m.positionPicker = newModel.(tools.PositionPickerModel)
+		return m, cmd
+	case stateAlign:
+		newModel, cmd := m.alignPicker.Update(msg)
+		m.alignPicker = newModel.(tools.AlignPickerModel)
+		return m, cmd
+	case statePresent:
+		newModel, cmd := m.presentPicker.Update(msg)
+		m.presentPicker = newModel.(tools.PresentPickerModel)
+		return m, cmd
 	}
 
 	return m, nil
 }
 
+// canvasTop is the screen row of the first canvas line: the title and the
+// grid header come before it
+const canvasTop = 2
+
+// canvasSize is the terminal area handed to GridModel.Render, minus the
+// details panel if it is shown
+func (m Model) canvasSize() (int, int) {
+	w, h := m.width, m.height-4
+	if m.showDetails {
+		if m.detailsBeside() {
+			w -= detailsWidth
+		} else {
+			h -= detailsMaxHeight
+		}
+	}
+	if h < 10 {
+		h = 10
+	}
+	return w, h
+}
+
 func (m Model) updateGrid(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		w, h := m.canvasSize()
+		col, row := msg.X, msg.Y-canvasTop
+
+		switch {
+		case msg.Button == tea.MouseButtonWheelUp:
+			m.grid.ZoomAt(w, h, col, row, 1.25)
+		case msg.Button == tea.MouseButtonWheelDown:
+			m.grid.ZoomAt(w, h, col, row, 1/1.25)
+		case msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft:
+			if id := m.grid.MonitorAt(w, h, col, row); id != "" {
+				m.grid.SelectedID = id
+				m.grid.StartDrag(w, h, col, row)
+			}
+		case msg.Action == tea.MouseActionMotion && m.grid.Dragging():
+			m.grid.Drag(w, h, col, row)
+		case msg.Action == tea.MouseActionRelease:
+			if m.grid.Dragging() {
+				m.status = m.relayout()
+			}
+			m.grid.EndDrag()
+		}
+
 	case tea.KeyMsg:
+		m.status = ""
+		confirmSave, confirmMode := m.confirmSave, m.confirmMode
+		m.confirmSave, m.confirmMode = false, false
+		before := m.layout()
+		w, h := m.canvasSize()
 		switch msg.String() {
 		case "ctrl+c", "q":
+			// Remember the viewport even if the layout wasn't saved
+			m.saveAppState()
 			return m, tea.Quit
 
 		case "tab":
-			currIdx := -1
-			for i, o := range m.outputs {
-				if o.Name == m.grid.SelectedID {
-					currIdx = i
-					break
-				}
-			}
-			if currIdx != -1 {
-				nextIdx := (currIdx + 1) % len(m.outputs)
-				m.grid.SelectedID = m.outputs[nextIdx].Name
-			}
-			if len(m.outputs) == 0 {
-				// Handle no output case
-			} else if currIdx == -1 {
-				m.grid.SelectedID = m.outputs[0].Name
-			}
+			m.grid.CycleSelection(1)
+		case "shift+tab":
+			m.grid.CycleSelection(-1)
+		case "alt+up", "alt+k":
+			m.grid.SelectDirection(0, -1)
+		case "alt+down", "alt+j":
+			m.grid.SelectDirection(0, 1)
+		case "alt+left", "alt+h":
+			m.grid.SelectDirection(-1, 0)
+		case "alt+right", "alt+l":
+			m.grid.SelectDirection(1, 0)
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			m.grid.SelectNumber(int(msg.String()[0] - '0'))
 
 		case "up", "k":
 			m.grid.MoveSelected(0, -1)
@@ -275,24 +537,61 @@ func (m Model) updateGrid(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "G", "g":
 			m.grid.CycleGrid()
 
+		// Viewport
+		case "+", "=":
+			m.grid.ZoomBy(1.25)
+		case "-":
+			m.grid.ZoomBy(1 / 1.25)
+		case "0":
+			m.grid.FitAll()
+		case "Z", "z":
+			m.grid.FocusSelected()
+		case "ctrl+up":
+			m.grid.Pan(w, h, 0, -0.25)
+		case "ctrl+down":
+			m.grid.Pan(w, h, 0, 0.25)
+		case "ctrl+left":
+			m.grid.Pan(w, h, -0.25, 0)
+		case "ctrl+right":
+			m.grid.Pan(w, h, 0.25, 0)
+
 		case "R", "r": // Open Scale Picker
 			if rule, ok := m.rules[m.grid.SelectedID]; ok {
 				m.state = stateScale
-				m.scalePicker = tools.NewScalePicker(rule.ID, rule.Scale, rule.Width, rule.Height)
+				ppi := m.edids[rule.ID].PPI(rule.Width, rule.Height)
+				m.scalePicker = tools.NewScalePicker(rule.ID, rule.Scale, rule.Width, rule.Height, rule.Transform, ppi)
 			}
 
 		case "F", "f": // Open Mode Picker
 			if rule, ok := m.rules[m.grid.SelectedID]; ok {
-				sysModes, _ := system.GetModes(rule.ID) // Ignore error, returns default list if fail
-				var toolModes []tools.Mode
-				for _, sm := range sysModes {
-					toolModes = append(toolModes, tools.Mode{Width: sm.Width, Height: sm.Height, Rate: sm.Rate})
+				if modes, ok := m.modeCache[rule.ID]; ok {
+					m.openModePicker(modes, nil)
+				} else if m.probingModes == "" {
+					m.probingModes = rule.ID
+					return m, tea.Batch(probeModes(rule.ID), m.startSpinner())
 				}
+			}
 
-				m.state = stateMode
-				m.modePicker = tools.NewModePicker(rule.ID, tools.Mode{Width: rule.Width, Height: rule.Height, Rate: rule.RefreshRate}, toolModes)
+		case "ctrl+r": // Probe the outputs again
+			if !m.probingOutputs {
+				return m, m.reprobe()
 			}
 
+		case "O", "o": // Presentation mode switcher
+			current := ""
+			if appState, err := state.Load(); err == nil {
+				current = appState.Presentation
+			}
+			m.state = statePresent
+			m.presentPicker = tools.NewPresentPicker(current)
+			m.confirmMode = confirmMode
+
+		case "I", "i": // Toggle the details panel
+			m.showDetails = !m.showDetails
+
+		case "P", "p": // Match perceived size to the selected monitor
+			m.status = m.matchScales()
+
 		case "M", "m":
 			var allNames []string
 			for _, o := range m.outputs {
@@ -301,36 +600,77 @@ func (m Model) updateGrid(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.state = stateMirror
 			m.mirrorPicker = tools.NewMirrorPicker(m.grid.SelectedID, allNames)
 
+		case " ": // Mark for align/distribute
+			m.grid.ToggleMark()
+		case "U", "u":
+			m.grid.ClearMarks()
+		case "A", "a": // Open align/distribute picker
+			m.state = stateAlign
+			m.alignPicker = tools.NewAlignPicker(m.grid.MarkedIDs())
+
+		case "X", "x": // Open exact position dialog
+			if rule, ok := m.rules[m.grid.SelectedID]; ok {
+				m.state = statePosition
+				m.positionPicker = tools.NewPositionPicker(rule.ID, rule.Rect(), m.positionTargets(), rule.Anchor)
+				return m, m.positionPicker.Init()
+			}
+
 		case "T", "t":
 			if rule, ok := m.rules[m.grid.SelectedID]; ok {
 				m.state = stateTransform
-				m.transformPicker = tools.NewTransformPicker(rule.ID, rule.Transform)
+				m.transformPicker = tools.NewTransformPicker(rule.ID, rule.Transform, rule.Width, rule.Height, rule.Scale)
 			}
 
 		case "V", "v": // Open VRR Picker
 			if rule, ok := m.rules[m.grid.SelectedID]; ok {
 				m.state = stateVRR
-				m.vrrPicker = tools.NewVRRPicker(rule.ID, rule.VariableRefreshRate)
+				m.vrrPicker = tools.NewVRRPicker(rule.ID, rule.VariableRefreshRate, m.vrrCapability(rule.ID), rule.RefreshRate)
 			}
 
+		case "C", "c": // Open config check panel
+			m.state = stateCheck
+			m.checkPanel = tools.NewCheckPanel(m.parser.FilePath, m.diagnostics())
+
+		case "E", "e": // Export to other formats
+			m.state = stateExport
+			m.exportPicker = tools.NewExportPicker(convert.ExportFormats, exportDir())
+
 		case "S", "s": // Save
-			// Save app state (GridSize only)
-			appState := state.AppState{
-				GridSize: m.grid.GridSize,
+			if warning := m.saveWarning(); warning != "" && !confirmSave {
+				m.confirmSave = true
+				m.status = fmt.Sprintf("Unverified settings (%s). Press S again to save anyway", warning)
+				return m, nil
 			}
-			if err := state.Save(appState); err != nil {
+			if err := m.saveAppState(); err != nil {
 				m.err = err
 			}
 
-			var rulesToSave []config.MonitorRule
-			for _, r := range m.rules {
-				rulesToSave = append(rulesToSave, r)
-			}
+			rulesToSave, skipped := m.savableRules()
 			err := m.parser.Save(rulesToSave)
 			if err != nil {
 				m.err = err
+			} else if _, err := m.parser.Parse(); err != nil {
+				// Re-read so Lines and Diagnostics match the file
+				m.err = err
+			}
+			// Remember the settings per physical monitor as well
+			if err := m.rememberMonitors(); err != nil && m.err == nil {
+				m.err = err
+			}
+			if m.err == nil {
+				m.guessed = make(map[string]string)
+				m.status = "Saved " + m.parser.FilePath
+				if len(skipped) > 0 {
+					m.status += fmt.Sprintf(", left out example outputs %s", strings.Join(skipped, ", "))
+				}
+			}
+		}
+
+		// Monitors attached to the one that moved follow it
+		if !maps.Equal(before, m.layout()) {
+			if status := m.relayout(); status != "" {
+				m.status = status
 			}
-			// Maybe show a "Saved!" message?
 		}
 	}
 	return m, nil
@@ -350,22 +690,184 @@ func (m Model) View() string {
 		return m.transformPicker.View()
 	case stateVRR:
 		return m.vrrPicker.View()
+	case stateCheck:
+		return m.checkPanel.View()
+	case stateExport:
+		return m.exportPicker.View()
+	case statePosition:
+		return m.positionPicker.View()
+	case stateAlign:
+		return m.alignPicker.View()
+	case statePresent:
+		return m.presentPicker.View()
 	}
 	return ""
 }
 
 func (m Model) viewGrid() string {
-	h := m.height - 4
-	if h < 10 {
-		h = 10
+	content := m.grid.Render(m.canvasSize())
+	if m.showDetails {
+		if m.detailsBeside() {
+			content = lipgloss.JoinHorizontal(lipgloss.Top, content, m.detailsPanel(detailsWidth))
+		} else {
+			content = lipgloss.JoinVertical(lipgloss.Left, content, m.detailsPanel(m.width))
+		}
 	}
 
-	content := m.grid.Render(m.width, h)
-
-	footer := "[Tab] Cycle  [Arrows] Move  [G] Grid  [R] Scale  [F] Mode  [T] Transform  [V] VRR  [M] Mirror  [S] Save  [Q] Quit"
-	if m.err != nil {
+	footer := "[Tab/1-9/Alt+Arrows] Select  [Arrows] Move  [X] Position  [Space/A] Mark/Align  [G] Grid  [+/-/0/Z] Zoom  [I] Details  [R] Scale  [P] Match DPI  [F] Mode  [T] Transform  [V] VRR  [M] Mirror  [O] Present  [C] Check  [E] Export  [S] Save  [Q] Quit"
+	switch {
+	case m.err != nil:
 		footer = fmt.Sprintf("Error: %v", m.err)
+	case m.probingOutputs:
+		footer = m.spinner.View() + " Probing outputs..."
+	case m.probingModes != "":
+		footer = m.spinner.View() + fmt.Sprintf(" Reading modes of %s...", m.probingModes)
+	case m.status != "":
+		footer = m.status
 	}
 
-	return fmt.Sprintf("MangoWC Spatial Config\n%s\n%s", content, footer)
+	title := "MangoWC Spatial Config"
+	if n := len(m.parser.Diagnostics); n > 0 {
+		title += fmt.Sprintf("  (%d config problem(s), press C)", n)
+	}
+	if banner := m.banner(); banner != "" {
+		title += "  " + banner
+	}
+
+	return fmt.Sprintf("%s\n%s\n%s", title, content, footer)
+}
+
+// diagnostics converts the parser diagnostics for the check panel
+func (m Model) diagnostics() []tools.Diagnostic {
+	var diags []tools.Diagnostic
+	for _, d := range m.parser.Diagnostics {
+		diags = append(diags, tools.Diagnostic{
+			Line:     d.Line,
+			Severity: d.Severity.String(),
+			Message:  d.Message,
+			Fixable:  d.Fixable,
+		})
+	}
+	return diags
+}
+
+// exportDir is where layouts exported from the TUI are written
+func exportDir() string {
+	return filepath.Join(filepath.Dir(state.GetStatePath()), "export")
+}
+
+// export writes the current rules in the given format and returns the path
+func (m Model) export(format string) (string, error) {
+	dir := exportDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	var rules []config.MonitorRule
+	for _, r := range m.rules {
+		rules = append(rules, r)
+	}
+
+	path := filepath.Join(dir, convert.ExportFileName(format))
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if err := convert.Export(format, file, "", rules); err != nil {
+		return "", err
+	}
+	if format == "wlr-randr" {
+		return path, os.Chmod(path, 0755)
+	}
+	return path, nil
+}
+
+// saveAppState persists the grid size and viewport, keeping the rest of
+// the state such as the presentation mode
+func (m Model) saveAppState() error {
+	appState, err := state.Load()
+	if err != nil {
+		appState = state.AppState{}
+	}
+	appState.GridSize = m.grid.GridSize
+	appState.Zoom = m.grid.Zoom
+	appState.PanX, appState.PanY = m.grid.PanX, m.grid.PanY
+	return state.Save(appState)
+}
+
+// positionTargets are the monitors the selected one can be placed against
+func (m Model) positionTargets() []tools.PositionTarget {
+	var ids []string
+	for id := range m.rules {
+		if id != m.grid.SelectedID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	var targets []tools.PositionTarget
+	for _, id := range ids {
+		targets = append(targets, tools.PositionTarget{ID: id, Rect: m.rules[id].Rect()})
+	}
+	return targets
+}
+
+// matchScales picks scales for the other monitors so text looks as big as
+// on the selected one, and returns a status line
+func (m Model) matchScales() string {
+	ref, ok := m.rules[m.grid.SelectedID]
+	if !ok {
+		return ""
+	}
+	ppi := make(map[string]float64)
+	for id, r := range m.rules {
+		if p := m.edids[id].PPI(r.Width, r.Height); p > 0 {
+			ppi[id] = p
+		}
+	}
+	if ppi[ref.ID] == 0 {
+		return fmt.Sprintf("%s has no physical size in its EDID", ref.ID)
+	}
+
+	skipped := config.MatchScales(m.rules, ref.ID, ppi)
+	m.relayout()
+	status := fmt.Sprintf("Matched scales to %s (%.0f dpi)", ref.ID, ppi[ref.ID]/ref.Scale)
+	if len(skipped) > 0 {
+		status += fmt.Sprintf(", no physical size for %s", strings.Join(skipped, ", "))
+	}
+	return status
+}
+
+// vrrCapability converts the probed adaptive sync support for the picker
+func (m Model) vrrCapability(id string) tools.VRRCapability {
+	info, ok := m.vrr[id]
+	return tools.VRRCapability{
+		Known:   ok,
+		Capable: info.Capable,
+		Likely:  info.Likely,
+		MinRate: info.MinRate,
+		MaxRate: info.MaxRate,
+		Source:  info.Source,
+	}
+}
+
+// layout is where each monitor is and how big it is, to tell whether a key
+// changed anything anchors depend on
+func (m Model) layout() map[string]config.Rect {
+	rects := make(map[string]config.Rect, len(m.rules))
+	for id, r := range m.rules {
+		rects[id] = r.Rect()
+	}
+	return rects
+}
+
+// relayout re-places anchored monitors after a size or position change and
+// returns a status line if some anchors couldn't be followed
+func (m Model) relayout() string {
+	if broken := config.Solve(m.rules); len(broken) > 0 {
+		return fmt.Sprintf("Anchors of %s point at a missing monitor or loop", strings.Join(broken, ", "))
+	}
+	return ""
 }
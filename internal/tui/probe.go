@@ -0,0 +1,225 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"mangomon/config"
+	"mangomon/internal/state"
+	"mangomon/internal/system"
+	"mangomon/internal/tui/tools"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// probeTimeout bounds each hardware query, so a hung mmsg can't freeze the UI
+const probeTimeout = 3 * time.Second
+
+// hotplugInterval is how often the connector status is polled
+const hotplugInterval = 2 * time.Second
+
+// outputsProbedMsg carries everything known about the connected outputs
+type outputsProbedMsg struct {
+	outputs []system.Output
+	edids   map[string]system.EDID
+	vrr     map[string]system.VRRInfo
+	status  string // Connector status at probe time, see hotplugMsg
+	err     error  // Listing the outputs failed, outputs are examples
+}
+
+// modesProbedMsg carries the modes of one output
+type modesProbedMsg struct {
+	output string
+	modes  []system.Mode
+	err    error // Reading the modes failed, modes are common ones
+}
+
+// hotplugMsg is the polled connector status
+type hotplugMsg struct {
+	status string
+	err    error
+}
+
+// withTimeout runs a probe that can't be cancelled itself, giving up on it
+// after probeTimeout
+func withTimeout[T any](ctx context.Context, probe func() (T, error)) (T, error) {
+	type result struct {
+		v   T
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		v, err := probe()
+		done <- result{v, err}
+	}()
+	select {
+	case r := <-done:
+		return r.v, r.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// probeOutputs lists the outputs and reads their EDID and VRR support
+func probeOutputs() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+		defer cancel()
+
+		msg := outputsProbedMsg{
+			edids: make(map[string]system.EDID),
+			vrr:   make(map[string]system.VRRInfo),
+		}
+		msg.status, _ = system.ConnectorStatus()
+		msg.outputs, msg.err = system.GetOutputsContext(ctx)
+		for _, out := range msg.outputs {
+			if edid, err := withTimeout(ctx, func() (system.EDID, error) { return system.GetEDID(out.Name) }); err == nil {
+				msg.edids[out.Name] = edid
+			}
+			if info, err := withTimeout(ctx, func() (system.VRRInfo, error) { return system.GetVRRInfo(out.Name) }); err == nil {
+				msg.vrr[out.Name] = info
+			}
+		}
+		return msg
+	}
+}
+
+// probeModes reads the modes of an output
+func probeModes(output string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+		defer cancel()
+
+		modes, err := withTimeout(ctx, func() ([]system.Mode, error) { return system.GetModes(output) })
+		return modesProbedMsg{output: output, modes: modes, err: err}
+	}
+}
+
+// watchHotplug polls the connector status once
+func watchHotplug() tea.Cmd {
+	return tea.Tick(hotplugInterval, func(time.Time) tea.Msg {
+		status, err := system.ConnectorStatus()
+		return hotplugMsg{status: status, err: err}
+	})
+}
+
+// busy reports whether a probe is running, for the spinner
+func (m Model) busy() bool {
+	return m.probingOutputs || m.probingModes != ""
+}
+
+// startSpinner keeps a single spinner loop running while probes are
+func (m *Model) startSpinner() tea.Cmd {
+	if m.spinning {
+		return nil
+	}
+	m.spinning = true
+	return m.spinner.Tick
+}
+
+// reprobe starts listing the outputs again, e.g. after a hotplug
+func (m *Model) reprobe() tea.Cmd {
+	m.probingOutputs = true
+	m.modeCache = make(map[string][]system.Mode)
+	return tea.Batch(probeOutputs(), m.startSpinner())
+}
+
+// applyOutputs takes in a finished output probe. Outputs without a rule get
+// one, preferring what was used last time with the same physical monitor,
+// then the defaults for its model.
+func (m *Model) applyOutputs(msg outputsProbedMsg) {
+	m.probingOutputs = false
+	m.outputs = msg.outputs
+	m.edids, m.vrr = msg.edids, msg.vrr
+	m.grid.EDIDs, m.grid.VRR = msg.edids, msg.vrr
+	m.grid.Fabricated = make(map[string]bool)
+	for _, out := range msg.outputs {
+		if out.Fabricated() {
+			m.grid.Fabricated[out.Name] = true
+		}
+	}
+	m.hotplugStatus = msg.status
+	m.probeErr = msg.err
+
+	appState, _ := state.Load()
+	library, libDiags, libErr := config.LoadLibrary(state.GetLibraryPath())
+
+	added := false
+	var remembered []string
+	for _, out := range m.outputs {
+		if _, ok := m.rules[out.Name]; ok {
+			continue
+		}
+		added = true
+		if rule, ok := rememberedRule(out.Name, m.edids, appState.Monitors); ok {
+			m.rules[out.Name] = rule
+			remembered = append(remembered, out.Name)
+			continue
+		}
+		if rule, ok := libraryRule(out.Name, m.edids, library, m.rules); ok {
+			m.rules[out.Name] = rule
+			continue
+		}
+		rule := defaultRule(out.Name, m.edids)
+		placeRight(&rule, m.rules)
+		m.rules[out.Name] = rule
+		if m.edids[out.Name].Preferred == nil && !out.Fabricated() {
+			m.guessed[out.Name] = "no native mode in the EDID, 1920x1080@60 is a guess"
+		}
+	}
+	// Anchors can point at monitors added above, whatever their order
+	for _, name := range remembered {
+		reattach(name, m.edids, m.rules)
+	}
+	if added {
+		config.Solve(m.rules)
+	}
+
+	if _, ok := m.rules[m.grid.SelectedID]; !ok && len(m.outputs) > 0 {
+		m.grid.SelectedID = m.outputs[0].Name
+	}
+
+	// Library problems only matter for monitors it would have been used
+	// for, so report them without getting in the way
+	if libErr != nil {
+		m.status = fmt.Sprintf("Model library: %v", libErr)
+	} else if len(libDiags) > 0 {
+		m.status = fmt.Sprintf("Model library %s, %s", state.GetLibraryPath(), libDiags[0])
+		if len(libDiags) > 1 {
+			m.status += fmt.Sprintf(" (+%d more)", len(libDiags)-1)
+		}
+	}
+}
+
+// openModePicker shows the modes of the selected output, noting if they
+// couldn't be read
+func (m *Model) openModePicker(modes []system.Mode, err error) {
+	rule, ok := m.rules[m.grid.SelectedID]
+	if !ok {
+		return
+	}
+	var toolModes []tools.Mode
+	for _, sm := range modes {
+		toolModes = append(toolModes, tools.Mode{
+			Width:      sm.Width,
+			Height:     sm.Height,
+			Rate:       sm.Rate,
+			Preferred:  sm.Preferred,
+			Interlaced: sm.Interlaced,
+			VRR:        sm.VRR,
+			PPI:        m.edids[rule.ID].PPI(sm.Width, sm.Height),
+			Guessed:    sm.Source == system.SourceFallback,
+		})
+	}
+
+	m.state = stateMode
+	m.modePicker = tools.NewModePicker(rule.ID, tools.Mode{Width: rule.Width, Height: rule.Height, Rate: rule.RefreshRate}, toolModes)
+	switch {
+	case err != nil && len(modes) > 0:
+		m.modePicker.Warning = fmt.Sprintf("Couldn't read the modes of %s (%v), showing common ones", rule.ID, err)
+	case err != nil:
+		m.modePicker.Warning = fmt.Sprintf("Couldn't read the modes of %s (%v), press C to enter one", rule.ID, err)
+	}
+}
@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"mangomon/config"
+	"mangomon/internal/system"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	bannerWarn  = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	bannerError = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+)
+
+// output returns the probed output with the given name
+func (m Model) output(id string) (system.Output, bool) {
+	for _, o := range m.outputs {
+		if o.Name == id {
+			return o, true
+		}
+	}
+	return system.Output{}, false
+}
+
+// banner explains when the outputs aren't what MangoWC reports, empty when
+// they are
+func (m Model) banner() string {
+	var parts []string
+	if dir := system.ReplayDir(); dir != "" {
+		parts = append(parts, bannerWarn.Render(fmt.Sprintf("Replaying %s, nothing is applied to MangoWC", dir)))
+	}
+	if m.probeErr != nil {
+		parts = append(parts, m.probeBanner())
+	}
+	if len(m.guessed) > 0 {
+		var ids []string
+		for id := range m.guessed {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		parts = append(parts, bannerWarn.Render(fmt.Sprintf("Unverified settings for %s, see I", strings.Join(ids, ", "))))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// probeBanner says what is shown instead when the outputs couldn't be listed
+func (m Model) probeBanner() string {
+	for _, o := range m.outputs {
+		if o.Fabricated() {
+			return bannerError.Render(fmt.Sprintf("⚠ No outputs found (%v): showing examples, their rules won't be saved. Ctrl+R to retry", m.probeErr))
+		}
+	}
+	return bannerWarn.Render(fmt.Sprintf("⚠ MangoWC not reachable (%v): outputs read from /sys/class/drm. Ctrl+R to retry", m.probeErr))
+}
+
+// outputSource describes where the selected monitor's output came from
+func (m Model) outputSource(id string) string {
+	o, ok := m.output(id)
+	switch {
+	case !ok:
+		return "not connected"
+	case o.Source == system.SourceLive:
+		return "connected"
+	case o.Source == system.SourceSysfs:
+		return "connected (kernel, MangoWC not asked)"
+	default:
+		return "example, not found on this machine"
+	}
+}
+
+// savableRules leaves out rules that were made up for example outputs; rules
+// already in the config are kept whatever was probed
+func (m Model) savableRules() (rules []config.MonitorRule, skipped []string) {
+	for id, r := range m.rules {
+		if o, ok := m.output(id); ok && o.Fabricated() {
+			if _, inFile := m.parser.RuleLines[id]; !inFile {
+				skipped = append(skipped, id)
+				continue
+			}
+		}
+		rules = append(rules, r)
+	}
+	sort.Strings(skipped)
+	return rules, skipped
+}
+
+// saveWarning lists settings that weren't read from the hardware, empty if
+// there are none
+func (m Model) saveWarning() string {
+	var ids []string
+	for id := range m.guessed {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var parts []string
+	for _, id := range ids {
+		parts = append(parts, fmt.Sprintf("%s: %s", id, m.guessed[id]))
+	}
+	return strings.Join(parts, "; ")
+}
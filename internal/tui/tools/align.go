@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"mangomon/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type AlignSelectedMsg struct {
+	Op config.ArrangeOp
+}
+
+type AlignCancelledMsg struct{}
+
+type AlignPickerModel struct {
+	Monitors []string
+	Selected int
+}
+
+func NewAlignPicker(monitors []string) AlignPickerModel {
+	return AlignPickerModel{
+		Monitors: monitors,
+	}
+}
+
+func (m AlignPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m AlignPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, func() tea.Msg { return AlignCancelledMsg{} }
+		case "up", "k":
+			if m.Selected > 0 {
+				m.Selected--
+			}
+		case "down", "j":
+			if m.Selected < len(config.ArrangeOps)-1 {
+				m.Selected++
+			}
+		case "enter":
+			if len(m.Monitors) >= 2 {
+				return m, func() tea.Msg { return AlignSelectedMsg{Op: config.ArrangeOps[m.Selected]} }
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m AlignPickerModel) View() string {
+	s := fmt.Sprintf("Arrange %s\n\n", strings.Join(m.Monitors, ", "))
+
+	if len(m.Monitors) < 2 {
+		return s + "Mark at least one more monitor with [Space] first.\n\n[Esc] Back"
+	}
+
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	normalStyle := lipgloss.NewStyle().PaddingLeft(2)
+
+	for i, op := range config.ArrangeOps {
+		cursor := "  "
+		if i == m.Selected {
+			cursor = "▶ "
+		}
+
+		if i == m.Selected {
+			s += selectedStyle.Render(cursor+op.String()) + "\n"
+		} else {
+			s += normalStyle.Render(op.String()) + "\n"
+		}
+	}
+
+	s += "\nThe layout is moved back to start at 0,0 afterwards.\n"
+	s += "\n[Enter] Apply  [Esc] Cancel"
+
+	return s
+}
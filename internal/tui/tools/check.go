@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Diagnostic is a config problem as shown in the check panel
+type Diagnostic struct {
+	Line     int
+	Severity string
+	Message  string
+	Fixable  bool
+}
+
+type CheckFixMsg struct{}
+
+type CheckClosedMsg struct{}
+
+type CheckPanelModel struct {
+	Path        string
+	Diagnostics []Diagnostic
+	Selected    int
+	Status      string
+}
+
+func NewCheckPanel(path string, diags []Diagnostic) CheckPanelModel {
+	return CheckPanelModel{
+		Path:        path,
+		Diagnostics: diags,
+	}
+}
+
+func (m CheckPanelModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m CheckPanelModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, func() tea.Msg { return CheckClosedMsg{} }
+		case "up", "k":
+			if m.Selected > 0 {
+				m.Selected--
+			}
+		case "down", "j":
+			if m.Selected < len(m.Diagnostics)-1 {
+				m.Selected++
+			}
+		case "f":
+			if m.fixable() > 0 {
+				return m, func() tea.Msg { return CheckFixMsg{} }
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m CheckPanelModel) fixable() int {
+	n := 0
+	for _, d := range m.Diagnostics {
+		if d.Fixable {
+			n++
+		}
+	}
+	return n
+}
+
+func (m CheckPanelModel) View() string {
+	s := fmt.Sprintf("Config Check: %s\n\n", m.Path)
+
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	normalStyle := lipgloss.NewStyle().PaddingLeft(2)
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+
+	if len(m.Diagnostics) == 0 {
+		s += "No problems found.\n"
+	}
+
+	for i, d := range m.Diagnostics {
+		cursor := "  "
+		if i == m.Selected {
+			cursor = "▶ "
+		}
+
+		sev := warnStyle.Render(d.Severity)
+		if d.Severity == "error" {
+			sev = errorStyle.Render(d.Severity)
+		}
+		line := fmt.Sprintf("line %d: %s: %s", d.Line, sev, d.Message)
+		if d.Fixable {
+			line += " (fixable)"
+		}
+
+		if i == m.Selected {
+			s += selectedStyle.Render(cursor) + line + "\n"
+		} else {
+			s += normalStyle.Render(line) + "\n"
+		}
+	}
+
+	if m.Status != "" {
+		s += "\n" + m.Status + "\n"
+	}
+
+	if n := m.fixable(); n > 0 {
+		s += fmt.Sprintf("\n[f] Fix %d problem(s)  [Esc] Close", n)
+	} else {
+		s += "\n[Esc] Close"
+	}
+
+	return s
+}
@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"fmt"
+	"strconv"
+
+	"mangomon/internal/cvt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Fields of the custom mode dialog, in tab order
+const (
+	customFieldWidth = iota
+	customFieldHeight
+	customFieldRate
+	customFieldBlanking
+	customFieldCount
+)
+
+// CustomModeModel is the custom mode dialog of the mode picker. It is not a
+// standalone tool: the picker forwards keys and reads Mode when confirmed.
+type CustomModeModel struct {
+	Inputs  [3]textinput.Model // Width, height, refresh
+	Focus   int
+	Reduced bool
+}
+
+func NewCustomMode(current Mode) CustomModeModel {
+	m := CustomModeModel{Reduced: true}
+	values := []string{
+		strconv.Itoa(current.Width),
+		strconv.Itoa(current.Height),
+		strconv.FormatFloat(current.Rate, 'f', -1, 64),
+	}
+	for i := range m.Inputs {
+		ti := textinput.New()
+		ti.Prompt = ""
+		ti.CharLimit = 8
+		ti.Width = 10
+		ti.SetValue(values[i])
+		m.Inputs[i] = ti
+	}
+	m.Inputs[0].Focus()
+	return m
+}
+
+// timing is the CVT timing for the current input, ok is false while the
+// inputs are incomplete
+func (m CustomModeModel) timing() (cvt.Timing, float64, bool) {
+	w, errW := strconv.Atoi(m.Inputs[0].Value())
+	h, errH := strconv.Atoi(m.Inputs[1].Value())
+	r, errR := strconv.ParseFloat(m.Inputs[2].Value(), 64)
+	if errW != nil || errH != nil || errR != nil || w < 320 || h < 200 || r < 1 || r > 1000 {
+		return cvt.Timing{}, 0, false
+	}
+	return cvt.Compute(w, h, r, m.Reduced), r, true
+}
+
+// Mode is the mode to write to the rule. The refresh rate is the one the
+// CVT timing really produces, not the one typed in.
+func (m CustomModeModel) Mode() (Mode, bool) {
+	t, _, ok := m.timing()
+	if !ok {
+		return Mode{}, false
+	}
+	rate, _ := strconv.ParseFloat(fmt.Sprintf("%.3f", t.Refresh()), 64)
+	return Mode{Width: t.HDisplay, Height: t.VDisplay, Rate: rate}, true
+}
+
+func (m *CustomModeModel) setFocus(f int) {
+	m.Focus = (f + customFieldCount) % customFieldCount
+	for i := range m.Inputs {
+		if i == m.Focus {
+			m.Inputs[i].Focus()
+		} else {
+			m.Inputs[i].Blur()
+		}
+	}
+}
+
+func (m CustomModeModel) Update(msg tea.Msg) (CustomModeModel, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "tab", "down":
+			m.setFocus(m.Focus + 1)
+			return m, nil
+		case "shift+tab", "up":
+			m.setFocus(m.Focus - 1)
+			return m, nil
+		case "left", "right", " ":
+			if m.Focus == customFieldBlanking {
+				m.Reduced = !m.Reduced
+				return m, nil
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	if m.Focus < len(m.Inputs) {
+		m.Inputs[m.Focus], cmd = m.Inputs[m.Focus].Update(msg)
+	}
+	return m, cmd
+}
+
+func (m CustomModeModel) View(monitor string) string {
+	s := fmt.Sprintf("Custom Mode for %s\n\n", monitor)
+
+	focusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+
+	label := func(field int, text string) string {
+		if m.Focus == field {
+			return focusStyle.Render("▶ " + text)
+		}
+		return "  " + text
+	}
+
+	s += label(customFieldWidth, "Width:   ") + m.Inputs[0].View() + "\n"
+	s += label(customFieldHeight, "Height:  ") + m.Inputs[1].View() + "\n"
+	s += label(customFieldRate, "Refresh: ") + m.Inputs[2].View() + " Hz\n"
+
+	blanking := "CVT"
+	if m.Reduced {
+		blanking = "CVT reduced blanking"
+	}
+	if m.Focus == customFieldBlanking {
+		blanking = focusStyle.Render("◀ " + blanking + " ▶")
+	}
+	s += label(customFieldBlanking, "Timing:  ") + blanking + "\n\n"
+
+	if t, requested, ok := m.timing(); ok {
+		s += fmt.Sprintf("Pixel clock: %.2f MHz\n", float64(t.Clock)/1000.0)
+		s += fmt.Sprintf("Actual refresh: %.3f Hz\n", t.Refresh())
+		s += dimStyle.Render(t.Modeline(requested)) + "\n"
+		if t.HDisplay != mustAtoi(m.Inputs[0].Value()) {
+			s += warnStyle.Render(fmt.Sprintf("Width rounded down to %d (multiple of 8)", t.HDisplay)) + "\n"
+		}
+	} else {
+		s += warnStyle.Render("Enter a width ≥ 320, height ≥ 200 and refresh rate") + "\n"
+	}
+
+	s += "\n" + dimStyle.Render("[Tab] Next field  [←/→] Timing  [Enter] Use mode  [Esc] Back")
+	return s
+}
+
+func mustAtoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type ExportSelectedMsg struct {
+	Format string
+}
+
+type ExportCancelledMsg struct{}
+
+type ExportPickerModel struct {
+	Formats  []string
+	Dir      string // Where the export will be written, for display
+	Selected int
+}
+
+func NewExportPicker(formats []string, dir string) ExportPickerModel {
+	return ExportPickerModel{
+		Formats: formats,
+		Dir:     dir,
+	}
+}
+
+func (m ExportPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m ExportPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, func() tea.Msg { return ExportCancelledMsg{} }
+		case "up", "k":
+			if m.Selected > 0 {
+				m.Selected--
+			}
+		case "down", "j":
+			if m.Selected < len(m.Formats)-1 {
+				m.Selected++
+			}
+		case "enter":
+			if len(m.Formats) > 0 {
+				return m, func() tea.Msg { return ExportSelectedMsg{Format: m.Formats[m.Selected]} }
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m ExportPickerModel) View() string {
+	s := "Export Layout\n\n"
+
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	normalStyle := lipgloss.NewStyle().PaddingLeft(2)
+
+	for i, format := range m.Formats {
+		cursor := "  "
+		if i == m.Selected {
+			cursor = "▶ "
+		}
+
+		if i == m.Selected {
+			s += selectedStyle.Render(cursor+format) + "\n"
+		} else {
+			s += normalStyle.Render(format) + "\n"
+		}
+	}
+
+	s += fmt.Sprintf("\nWritten to %s\n", m.Dir)
+	s += "\n[Enter] Export  [Esc] Cancel"
+
+	return s
+}
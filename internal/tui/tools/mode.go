@@ -2,7 +2,11 @@ package tools
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"strings"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -10,32 +14,176 @@ import (
 type Mode struct {
 	Width, Height int
 	Rate          float64
+	Preferred     bool
+	Interlaced    bool
+	VRR           bool
+	PPI           float64 // Physical density at this size, 0 if unknown
+	Guessed       bool    // Not reported by the monitor, it may not support it
 }
 
 func (m Mode) String() string {
 	return fmt.Sprintf("%dx%d @ %.2fHz", m.Width, m.Height, m.Rate)
 }
 
+// Same compares the timing, not the markers. Rates are saved rounded, so
+// they only have to be close.
+func (m Mode) Same(o Mode) bool {
+	return m.Width == o.Width && m.Height == o.Height && math.Abs(m.Rate-o.Rate) < 0.01
+}
+
 type ModeSelectedMsg struct {
 	Mode Mode
 }
 
 type ModeCancelledMsg struct{}
 
+// modeRow is a line of the picker: a resolution header or a mode under it
+type modeRow struct {
+	header string
+	mode   Mode
+}
+
+// maxModeRows keeps long mode lists from scrolling the picker off screen
+const maxModeRows = 20
+
 type ModePickerModel struct {
 	Monitor  string
 	Modes    []Mode
-	Selected int
+	Selected int // Index into rows, always on a mode
 	Current  Mode
+
+	SortByRate  bool
+	Filtering   bool
+	FilterInput textinput.Model
+
+	CustomMode   bool
+	CustomDialog CustomModeModel
+
+	Warning string // Shown above the list, e.g. when the modes are guesses
+
+	rows []modeRow
 }
 
 func NewModePicker(monitor string, current Mode, modes []Mode) ModePickerModel {
-	return ModePickerModel{
-		Monitor:  monitor,
-		Modes:    modes,
-		Selected: 0,
-		Current:  current,
+	ti := textinput.New()
+	ti.Placeholder = "e.g. 2560 or 144"
+	ti.CharLimit = 20
+	ti.Width = 20
+
+	m := ModePickerModel{
+		Monitor:     monitor,
+		Modes:       modes,
+		Current:     current,
+		FilterInput: ti,
+	}
+	m.rebuild()
+	m.selectMode(current)
+	return m
+}
+
+// rebuild groups the filtered modes by resolution, in the current sort
+// order, and keeps the cursor on a mode row
+func (m *ModePickerModel) rebuild() {
+	filter := strings.ToLower(strings.TrimSpace(m.FilterInput.Value()))
+
+	type group struct {
+		w, h    int
+		ppi     float64
+		maxRate float64
+		modes   []Mode
+	}
+	var groups []*group
+	byRes := make(map[[2]int]*group)
+	for _, mode := range m.Modes {
+		if filter != "" && !strings.Contains(strings.ToLower(modeLabel(mode)), filter) {
+			continue
+		}
+		key := [2]int{mode.Width, mode.Height}
+		g, ok := byRes[key]
+		if !ok {
+			g = &group{w: mode.Width, h: mode.Height, ppi: mode.PPI}
+			byRes[key] = g
+			groups = append(groups, g)
+		}
+		g.modes = append(g.modes, mode)
+		if mode.Rate > g.maxRate {
+			g.maxRate = mode.Rate
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if m.SortByRate && a.maxRate != b.maxRate {
+			return a.maxRate > b.maxRate
+		}
+		return a.w*a.h > b.w*b.h
+	})
+
+	m.rows = nil
+	for _, g := range groups {
+		sort.SliceStable(g.modes, func(i, j int) bool { return g.modes[i].Rate > g.modes[j].Rate })
+		header := fmt.Sprintf("%dx%d", g.w, g.h)
+		if g.ppi > 0 {
+			header += fmt.Sprintf("  %.0f ppi", g.ppi)
+		}
+		m.rows = append(m.rows, modeRow{header: header})
+		for _, mode := range g.modes {
+			m.rows = append(m.rows, modeRow{mode: mode})
+		}
+	}
+
+	if m.Selected >= len(m.rows) {
+		m.Selected = len(m.rows) - 1
+	}
+	m.move(0)
+}
+
+// selectMode puts the cursor on the given mode if it is listed
+func (m *ModePickerModel) selectMode(mode Mode) {
+	for i, row := range m.rows {
+		if row.header == "" && row.mode.Same(mode) {
+			m.Selected = i
+			return
+		}
+	}
+}
+
+// move steps the cursor by delta mode rows, skipping headers
+func (m *ModePickerModel) move(delta int) {
+	if len(m.rows) == 0 {
+		m.Selected = 0
+		return
+	}
+	step := 1
+	if delta < 0 {
+		step = -1
+	}
+	i := m.Selected
+	for n := abs(delta); n > 0; n-- {
+		next := i + step
+		for next >= 0 && next < len(m.rows) && m.rows[next].header != "" {
+			next += step
+		}
+		if next < 0 || next >= len(m.rows) {
+			break
+		}
+		i = next
+	}
+	// Never rest on a header
+	for i < len(m.rows)-1 && m.rows[i].header != "" {
+		i++
+	}
+	for i > 0 && m.rows[i].header != "" {
+		i--
+	}
+	m.Selected = i
+}
+
+func (m ModePickerModel) selectedMode() (Mode, bool) {
+	if m.Selected < 0 || m.Selected >= len(m.rows) || m.rows[m.Selected].header != "" {
+		return Mode{}, false
 	}
+	return m.rows[m.Selected].mode, true
 }
 
 func (m ModePickerModel) Init() tea.Cmd {
@@ -43,59 +191,193 @@ func (m ModePickerModel) Init() tea.Cmd {
 }
 
 func (m ModePickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	// Custom Mode Dialog
+	if m.CustomMode {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch msg.String() {
+			case "esc":
+				m.CustomMode = false
+				return m, nil
+			case "enter":
+				if mode, ok := m.CustomDialog.Mode(); ok {
+					return m, func() tea.Msg { return ModeSelectedMsg{Mode: mode} }
+				}
+				return m, nil
+			}
+		}
+		m.CustomDialog, cmd = m.CustomDialog.Update(msg)
+		return m, cmd
+	}
+
+	// Filter Input Mode
+	if m.Filtering {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch msg.String() {
+			case "esc":
+				m.Filtering = false
+				m.FilterInput.Blur()
+				m.FilterInput.SetValue("")
+				m.rebuild()
+				return m, nil
+			case "enter", "down", "up":
+				// Keep the filter, go back to the list
+				m.Filtering = false
+				m.FilterInput.Blur()
+				return m, nil
+			}
+		}
+		m.FilterInput, cmd = m.FilterInput.Update(msg)
+		m.rebuild()
+		return m, cmd
+	}
+
+	// List Mode
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "q", "ctrl+c", "esc":
 			return m, func() tea.Msg { return ModeCancelledMsg{} }
-		case "up", "k":
-			if m.Selected > 0 {
-				m.Selected--
+		case "c":
+			m.CustomMode = true
+			m.CustomDialog = NewCustomMode(m.Current)
+			return m, textinput.Blink
+		case "/":
+			m.Filtering = true
+			m.FilterInput.Focus()
+			return m, textinput.Blink
+		case "s":
+			mode, ok := m.selectedMode()
+			m.SortByRate = !m.SortByRate
+			m.rebuild()
+			if ok {
+				m.selectMode(mode)
 			}
+		case "up", "k":
+			m.move(-1)
 		case "down", "j":
-			if m.Selected < len(m.Modes)-1 {
-				m.Selected++
-			}
+			m.move(1)
 		case "home", "g":
 			m.Selected = 0
+			m.move(0)
 		case "end", "G":
-			m.Selected = len(m.Modes) - 1
+			m.Selected = len(m.rows) - 1
+			m.move(0)
 		case "enter":
-			return m, func() tea.Msg { return ModeSelectedMsg{Mode: m.Modes[m.Selected]} }
+			if mode, ok := m.selectedMode(); ok {
+				return m, func() tea.Msg { return ModeSelectedMsg{Mode: mode} }
+			}
 		}
 	}
 	return m, nil
 }
 
+// modeLabel is the text of a mode row, also used for filtering
+func modeLabel(mode Mode) string {
+	s := fmt.Sprintf("%dx%d @ %.2fHz", mode.Width, mode.Height, mode.Rate)
+	if mode.Interlaced {
+		s += " interlaced"
+	}
+	return s
+}
+
 func (m ModePickerModel) View() string {
+	if m.CustomMode {
+		return m.CustomDialog.View(m.Monitor)
+	}
+
 	s := fmt.Sprintf("Select Mode for %s\n\n", m.Monitor)
+	if m.Warning != "" {
+		s += lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(m.Warning) + "\n\n"
+	}
 
 	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
 	normalStyle := lipgloss.NewStyle().PaddingLeft(2)
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Bold(true)
 	currentStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	markerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+
+	if m.Filtering || m.FilterInput.Value() != "" {
+		s += fmt.Sprintf("Filter: %s\n\n", m.FilterInput.View())
+	}
+	if len(m.rows) == 0 {
+		s += "No modes match.\n"
+	}
+
+	// Window the list around the cursor
+	start := 0
+	if len(m.rows) > maxModeRows {
+		start = m.Selected - maxModeRows/2
+		start = max(0, min(start, len(m.rows)-maxModeRows))
+	}
+	end := min(len(m.rows), start+maxModeRows)
+	if start > 0 {
+		s += headerStyle.Render("  ↑ more") + "\n"
+	}
+
+	for i := start; i < end; i++ {
+		row := m.rows[i]
+		if row.header != "" {
+			s += headerStyle.Render(row.header) + "\n"
+			continue
+		}
 
-	for i, mode := range m.Modes {
 		cursor := "  "
 		if i == m.Selected {
 			cursor = "▶ "
 		}
 
-		modeStr := mode.String()
-		indicator := ""
-		if mode.Width == m.Current.Width && mode.Height == m.Current.Height && mode.Rate == m.Current.Rate {
-			indicator = currentStyle.Render(" (current)")
+		line := fmt.Sprintf("  %.2fHz", row.mode.Rate)
+		var markers []string
+		if row.mode.Preferred {
+			markers = append(markers, "★ native")
+		}
+		if row.mode.Interlaced {
+			markers = append(markers, "interlaced")
+		}
+		if row.mode.VRR {
+			markers = append(markers, "VRR")
+		}
+		if row.mode.Guessed {
+			markers = append(markers, "? unverified")
+		}
+		if len(markers) > 0 {
+			line += markerStyle.Render(" [" + strings.Join(markers, ", ") + "]")
+		}
+		if row.mode.Same(m.Current) {
+			line += currentStyle.Render(" (current)")
 		}
-
-		line := fmt.Sprintf("%s%s", modeStr, indicator)
 
 		if i == m.Selected {
-			s += selectedStyle.Render(cursor+line) + "\n"
+			s += selectedStyle.Render(cursor) + selectedStyle.Render(line) + "\n"
 		} else {
 			s += normalStyle.Render(line) + "\n"
 		}
 	}
+	if end < len(m.rows) {
+		s += headerStyle.Render("  ↓ more") + "\n"
+	}
 
-	s += "\n[Enter] Select  [Esc] Cancel"
+	sortName := "resolution"
+	if m.SortByRate {
+		sortName = "refresh rate"
+	}
+	s += fmt.Sprintf("\nSorted by %s\n", sortName)
+	for _, mode := range m.Modes {
+		if mode.Guessed {
+			s += "Unverified modes are common values the monitor didn't report, it may not support them\n"
+			break
+		}
+	}
+	s += "\n[Enter] Select  [/] Filter  [s] Sort  [c] Custom  [Esc] Cancel"
 
 	return s
 }
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
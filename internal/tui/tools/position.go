@@ -0,0 +1,253 @@
+package tools
+
+import (
+	"fmt"
+	"strconv"
+
+	"mangomon/config"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type PositionSelectedMsg struct {
+	X, Y   int
+	Anchor config.Anchor // Set when the monitor should stay attached to the target
+}
+
+type PositionCancelledMsg struct{}
+
+// PositionTarget is another monitor the selected one can be placed against
+type PositionTarget struct {
+	ID   string
+	Rect config.Rect
+}
+
+// Fields of the position dialog, in tab order
+const (
+	posFieldX = iota
+	posFieldY
+	posFieldRelation
+	posFieldTarget
+	posFieldAlign
+	posFieldAttach
+	posFieldCount
+)
+
+type PositionPickerModel struct {
+	Monitor       string
+	Width, Height int // Logical size of the monitor being placed
+	Targets       []PositionTarget
+
+	XInput, YInput textinput.Model
+	Focus          int
+
+	Relation config.Relation
+	Target   int
+	Align    config.Alignment
+	Attached bool // Keep the relative placement when sizes change
+}
+
+// NewPositionPicker opens on the monitor's anchor if it has one
+func NewPositionPicker(monitor string, current config.Rect, targets []PositionTarget, anchor config.Anchor) PositionPickerModel {
+	newInput := func(v int) textinput.Model {
+		ti := textinput.New()
+		ti.Prompt = ""
+		ti.CharLimit = 7
+		ti.Width = 10
+		ti.SetValue(strconv.Itoa(v))
+		return ti
+	}
+
+	m := PositionPickerModel{
+		Monitor: monitor,
+		Width:   current.W,
+		Height:  current.H,
+		Targets: targets,
+		XInput:  newInput(current.X),
+		YInput:  newInput(current.Y),
+	}
+	if anchor.IsSet() {
+		for i, t := range targets {
+			if t.ID == anchor.Target {
+				m.Target, m.Relation, m.Align, m.Attached = i, anchor.Relation, anchor.Align, true
+			}
+		}
+	}
+	m.XInput.Focus()
+	return m
+}
+
+func (m PositionPickerModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// position is what the dialog would confirm, ok is false while the inputs
+// don't hold integers
+func (m PositionPickerModel) position() (int, int, bool) {
+	x, errX := strconv.Atoi(m.XInput.Value())
+	y, errY := strconv.Atoi(m.YInput.Value())
+	return x, y, errX == nil && errY == nil
+}
+
+// applyRelative writes the position from the relative fields into X/Y
+func (m *PositionPickerModel) applyRelative() {
+	if len(m.Targets) == 0 {
+		return
+	}
+	x, y := config.PlaceRelative(m.Width, m.Height, m.Targets[m.Target].Rect, m.Relation, m.Align)
+	m.XInput.SetValue(strconv.Itoa(x))
+	m.YInput.SetValue(strconv.Itoa(y))
+	m.Attached = true
+}
+
+// anchor is what the dialog would attach the monitor to, if anything
+func (m PositionPickerModel) anchor() config.Anchor {
+	if !m.Attached || len(m.Targets) == 0 {
+		return config.Anchor{}
+	}
+	return config.Anchor{Target: m.Targets[m.Target].ID, Relation: m.Relation, Align: m.Align}
+}
+
+// fieldCount is the number of fields shown: the relative placement ones
+// only appear when there is something to place against
+func (m PositionPickerModel) fieldCount() int {
+	if len(m.Targets) == 0 {
+		return posFieldY + 1
+	}
+	return posFieldCount
+}
+
+func (m *PositionPickerModel) setFocus(f int) {
+	n := m.fieldCount()
+	m.Focus = (f + n) % n
+	m.XInput.Blur()
+	m.YInput.Blur()
+	switch m.Focus {
+	case posFieldX:
+		m.XInput.Focus()
+	case posFieldY:
+		m.YInput.Focus()
+	}
+}
+
+func (m PositionPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return m, func() tea.Msg { return PositionCancelledMsg{} }
+		case "tab", "down":
+			m.setFocus(m.Focus + 1)
+			return m, nil
+		case "shift+tab", "up":
+			m.setFocus(m.Focus - 1)
+			return m, nil
+		case "enter":
+			if x, y, ok := m.position(); ok {
+				anchor := m.anchor()
+				return m, func() tea.Msg { return PositionSelectedMsg{X: x, Y: y, Anchor: anchor} }
+			}
+			return m, nil
+		case "left", "right":
+			step := 1
+			if msg.String() == "left" {
+				step = -1
+			}
+			switch m.Focus {
+			case posFieldRelation:
+				m.Relation = config.Relation((int(m.Relation) + step + 4) % 4)
+				m.applyRelative()
+				return m, nil
+			case posFieldTarget:
+				if len(m.Targets) > 0 {
+					m.Target = (m.Target + step + len(m.Targets)) % len(m.Targets)
+					m.applyRelative()
+				}
+				return m, nil
+			case posFieldAlign:
+				m.Align = config.Alignment((int(m.Align) + step + 3) % 3)
+				m.applyRelative()
+				return m, nil
+			case posFieldAttach:
+				if m.Attached {
+					m.Attached = false
+				} else {
+					m.applyRelative()
+				}
+				return m, nil
+			}
+		}
+	}
+
+	x, y := m.XInput.Value(), m.YInput.Value()
+	switch m.Focus {
+	case posFieldX:
+		m.XInput, cmd = m.XInput.Update(msg)
+	case posFieldY:
+		m.YInput, cmd = m.YInput.Update(msg)
+	}
+	// Typing a position detaches the monitor
+	if m.XInput.Value() != x || m.YInput.Value() != y {
+		m.Attached = false
+	}
+	return m, cmd
+}
+
+func (m PositionPickerModel) View() string {
+	s := fmt.Sprintf("Position %s (%dx%d logical)\n\n", m.Monitor, m.Width, m.Height)
+
+	focusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+
+	label := func(field int, text string) string {
+		if m.Focus == field {
+			return focusStyle.Render("▶ " + text)
+		}
+		return "  " + text
+	}
+	choice := func(field int, text string) string {
+		if m.Focus == field {
+			return focusStyle.Render("◀ " + text + " ▶")
+		}
+		return text
+	}
+
+	s += label(posFieldX, "X: ") + m.XInput.View() + "\n"
+	s += label(posFieldY, "Y: ") + m.YInput.View() + "\n\n"
+
+	if len(m.Targets) > 0 {
+		s += "  Place relative to another monitor:\n"
+		s += label(posFieldRelation, "Side:   ") + choice(posFieldRelation, m.Relation.String()) + "\n"
+		s += label(posFieldTarget, "Target: ") + choice(posFieldTarget, m.Targets[m.Target].ID) + "\n"
+		s += label(posFieldAlign, "Align:  ") + choice(posFieldAlign, m.Align.Name(m.Relation)) + "\n"
+		attached := "no, fixed position"
+		if m.Attached {
+			attached = "yes, follows size changes"
+		}
+		s += label(posFieldAttach, "Attach: ") + choice(posFieldAttach, attached) + "\n"
+	}
+
+	if x, y, ok := m.position(); ok {
+		s += fmt.Sprintf("\nResult: %d, %d  (to %d, %d)\n", x, y, x+m.Width, y+m.Height)
+		rect := config.Rect{X: x, Y: y, W: m.Width, H: m.Height}
+		for _, t := range m.Targets {
+			if rect != t.Rect && rect.Overlaps(t.Rect) {
+				s += warnStyle.Render(fmt.Sprintf("Overlaps %s", t.ID)) + "\n"
+			}
+		}
+	} else {
+		s += "\n" + warnStyle.Render("X and Y must be whole numbers") + "\n"
+	}
+
+	help := "[Tab] Next field  [Enter] Confirm  [Esc] Cancel"
+	if len(m.Targets) > 0 {
+		help = "[Tab] Next field  [←/→] Change side/target/align/attach  [Enter] Confirm  [Esc] Cancel"
+	}
+	s += "\n" + dimStyle.Render(help)
+
+	return s
+}
@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"mangomon/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type PresentSelectedMsg struct {
+	Mode config.Presentation
+}
+
+type PresentCancelledMsg struct{}
+
+type PresentPickerModel struct {
+	Current  string // Mode last set, "" if never switched
+	Selected int
+}
+
+var presentationHelp = []string{
+	"Extend       all monitors side by side",
+	"Mirror       every monitor shows the internal panel",
+	"External     turn the internal panel off",
+	"Internal     turn the external monitors off",
+}
+
+func NewPresentPicker(current string) PresentPickerModel {
+	m := PresentPickerModel{Current: current}
+	for i, p := range config.Presentations {
+		if p.String() == current {
+			m.Selected = i
+		}
+	}
+	return m
+}
+
+func (m PresentPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m PresentPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, func() tea.Msg { return PresentCancelledMsg{} }
+		case "up", "k":
+			if m.Selected > 0 {
+				m.Selected--
+			}
+		case "down", "j":
+			if m.Selected < len(config.Presentations)-1 {
+				m.Selected++
+			}
+		case "1", "2", "3", "4":
+			m.Selected = int(msg.String()[0] - '1')
+			return m, func() tea.Msg { return PresentSelectedMsg{Mode: config.Presentations[m.Selected]} }
+		case "enter":
+			return m, func() tea.Msg { return PresentSelectedMsg{Mode: config.Presentations[m.Selected]} }
+		}
+	}
+	return m, nil
+}
+
+func (m PresentPickerModel) View() string {
+	s := "Presentation Mode\n\n"
+
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	normalStyle := lipgloss.NewStyle().PaddingLeft(2)
+	currentStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+
+	for i, p := range config.Presentations {
+		cursor := "  "
+		if i == m.Selected {
+			cursor = "▶ "
+		}
+
+		line := string(rune('1'+i)) + " " + presentationHelp[i]
+		if p.String() == m.Current {
+			line += currentStyle.Render(" (current)")
+		}
+
+		if i == m.Selected {
+			s += selectedStyle.Render(cursor+line) + "\n"
+		} else {
+			s += normalStyle.Render(line) + "\n"
+		}
+	}
+
+	s += "\nThe layout is saved and applied right away. Switching back to extend\n"
+	s += "restores the layout you had before.\n"
+	s += "\n[1-4/Enter] Switch  [Esc] Cancel"
+
+	return s
+}
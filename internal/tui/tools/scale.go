@@ -2,8 +2,12 @@ package tools
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"strconv"
 
+	"mangomon/config"
+
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -15,33 +19,105 @@ type ScaleSelectedMsg struct {
 
 type ScaleCancelledMsg struct{}
 
+const scaleDenominator = config.ScaleDenominator
+
+// targetDPI is the effective density the recommendation aims for, roughly
+// what a 24" 1080p or 27" 1440p monitor has at scale 1
+const targetDPI = 110
+
 type ScalePickerModel struct {
-	Monitor  string
-	Scales   []float64
-	Selected int
-	Current  float64
+	Monitor     string
+	Scales      []float64
+	Selected    int
+	Current     float64
+	Recommended float64 // 0 without a physical size
 
 	CustomMode  bool
 	CustomInput textinput.Model
 
-	Width, Height int
+	Width, Height int     // Mode size after the transform
+	PPI           float64 // Physical density, 0 if unknown
 }
 
-func NewScalePicker(monitor string, current float64, width, height int) ScalePickerModel {
+// NewScalePicker lists the usual scales plus every multiple of 1/120 that
+// gives a whole logical size for the mode, and starts on the current scale.
+// ppi is the physical pixel density from the EDID, 0 if unknown.
+func NewScalePicker(monitor string, current float64, width, height, transform int, ppi float64) ScalePickerModel {
 	ti := textinput.New()
 	ti.Placeholder = "1.0"
-	ti.CharLimit = 5
+	ti.CharLimit = 6
 	ti.Width = 10
 
-	return ScalePickerModel{
+	if transform%2 == 1 {
+		width, height = height, width
+	}
+
+	m := ScalePickerModel{
 		Monitor:     monitor,
-		Scales:      []float64{0.5, 0.75, 1.0, 1.25, 1.5, 1.75, 2.0, 2.5, 3.0},
-		Selected:    2,
 		Current:     current,
 		CustomInput: ti,
 		Width:       width,
 		Height:      height,
+		PPI:         ppi,
+	}
+
+	seen := make(map[int]bool)
+	add := func(s float64) {
+		k := int(math.Round(s * scaleDenominator))
+		if k > 0 && !seen[k] {
+			seen[k] = true
+			m.Scales = append(m.Scales, float64(k)/scaleDenominator)
+		}
+	}
+	for _, s := range []float64{0.5, 0.75, 1.0, 1.25, 1.5, 1.75, 2.0, 2.5, 3.0} {
+		add(s)
+	}
+	for k := scaleDenominator / 2; k <= 3*scaleDenominator; k++ {
+		if m.exact(float64(k) / scaleDenominator) {
+			add(float64(k) / scaleDenominator)
+		}
+	}
+	if current > 0 {
+		add(current)
+	}
+	sort.Float64s(m.Scales)
+
+	if ppi > 0 {
+		m.Recommended = m.recommend(ppi / targetDPI)
+	}
+
+	m.Selected = m.indexOf(1.0)
+	if current > 0 {
+		m.Selected = m.indexOf(current)
+	}
+	return m
+}
+
+// exact reports whether the scale gives a whole logical size
+func (m ScalePickerModel) exact(scale float64) bool {
+	return config.ExactScale(m.Width, m.Height, scale)
+}
+
+// recommend picks the exact scale closest to ideal, falling back to the
+// closest listed one if the mode has no exact scales
+func (m ScalePickerModel) recommend(ideal float64) float64 {
+	best, bestExact := 0.0, false
+	for _, s := range m.Scales {
+		e := m.exact(s)
+		if best == 0 || (e && !bestExact) || (e == bestExact && math.Abs(s-ideal) < math.Abs(best-ideal)) {
+			best, bestExact = s, e
+		}
+	}
+	return best
+}
+
+func (m ScalePickerModel) indexOf(scale float64) int {
+	for i, s := range m.Scales {
+		if math.Abs(s-scale) < 0.5/scaleDenominator {
+			return i
+		}
 	}
+	return 0
 }
 
 func (m ScalePickerModel) Init() tea.Cmd {
@@ -63,6 +139,8 @@ func (m ScalePickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "enter":
 				val := m.CustomInput.Value()
 				if s, err := strconv.ParseFloat(val, 64); err == nil && s > 0 && s <= 10 {
+					// Round to what the compositor will use anyway
+					s = math.Round(s*scaleDenominator) / scaleDenominator
 					return m, func() tea.Msg { return ScaleSelectedMsg{Scale: s} }
 				}
 			}
@@ -93,6 +171,10 @@ func (m ScalePickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.Selected = 0
 		case "end", "G":
 			m.Selected = len(m.Scales) - 1
+		case "r":
+			if m.Recommended > 0 {
+				m.Selected = m.indexOf(m.Recommended)
+			}
 		case "enter":
 			return m, func() tea.Msg { return ScaleSelectedMsg{Scale: m.Scales[m.Selected]} }
 
@@ -107,37 +189,68 @@ func (m ScalePickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// logicalSize is the size in the layout, with a ~ when it isn't whole
+func (m ScalePickerModel) logicalSize(scale float64) string {
+	w := float64(m.Width) / scale
+	h := float64(m.Height) / scale
+	if m.exact(scale) {
+		return fmt.Sprintf("%dx%d", int(w), int(h))
+	}
+	return fmt.Sprintf("~%.1fx%.1f", w, h)
+}
+
 func (m ScalePickerModel) View() string {
 	if m.CustomMode {
 		return fmt.Sprintf(
-			"Enter Custom Scale for %s:\n\n%s\n\n(Enter to confirm, Esc to cancel)",
+			"Enter Custom Scale for %s:\n\n%s\n\n(Enter to confirm, Esc to cancel; rounded to 1/%d)",
 			m.Monitor,
 			m.CustomInput.View(),
+			scaleDenominator,
 		)
 	}
 
-	s := fmt.Sprintf("Select Scale for %s\n\n", m.Monitor)
+	s := fmt.Sprintf("Select Scale for %s (%dx%d)\n\n", m.Monitor, m.Width, m.Height)
 
 	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true) // Pink/Orange
 	normalStyle := lipgloss.NewStyle().PaddingLeft(2)
 	currentStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42")) // Green
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 
-	for i, scale := range m.Scales {
+	// Window the list around the cursor
+	const maxRows = 16
+	start := 0
+	if len(m.Scales) > maxRows {
+		start = max(0, min(m.Selected-maxRows/2, len(m.Scales)-maxRows))
+	}
+	end := min(len(m.Scales), start+maxRows)
+	if start > 0 {
+		s += dimStyle.Render("  ↑ more") + "\n"
+	}
+
+	for i := start; i < end; i++ {
+		scale := m.Scales[i]
 		cursor := "  "
 		if i == m.Selected {
 			cursor = "▶ "
 		}
 
-		scaleStr := fmt.Sprintf("%.2fx", scale)
+		line := fmt.Sprintf("%-7s %-15s", config.FormatScale(scale)+"x", m.logicalSize(scale))
+		if m.PPI > 0 {
+			line += fmt.Sprintf(" %4.0f dpi", m.PPI/scale)
+		}
+
 		indicator := ""
 		if scale == 1.0 {
 			indicator = " (native)"
 		}
-		if scale == m.Current {
-			indicator = currentStyle.Render(" (current)")
+		if scale == m.Recommended {
+			indicator += " ★ recommended"
+		}
+		if math.Abs(scale-m.Current) < 0.5/scaleDenominator {
+			indicator += currentStyle.Render(" (current)")
 		}
 
-		line := fmt.Sprintf("%s%s", scaleStr, indicator)
+		line = fmt.Sprintf("%s%s", line, indicator)
 
 		if i == m.Selected {
 			s += selectedStyle.Render(cursor+line) + "\n"
@@ -145,13 +258,20 @@ func (m ScalePickerModel) View() string {
 			s += normalStyle.Render(line) + "\n"
 		}
 	}
+	if end < len(m.Scales) {
+		s += dimStyle.Render("  ↓ more") + "\n"
+	}
 
 	sel := m.Scales[m.Selected]
-	effW := int(float64(m.Width) / sel)
-	effH := int(float64(m.Height) / sel)
-
-	s += fmt.Sprintf("\nPhysical: %dx%d -> Effective: %dx%d\n", m.Width, m.Height, effW, effH)
-	s += "\n[c] Custom  [1/2] Quick Select  [Esc] Cancel"
+	s += fmt.Sprintf("\nPhysical: %dx%d -> Effective: %s", m.Width, m.Height, m.logicalSize(sel))
+	if !m.exact(sel) {
+		s += " (not whole, may blur edges)"
+	}
+	s += "\n"
+	if m.PPI > 0 {
+		s += fmt.Sprintf("Panel density: %.0f ppi, recommended for ~%d dpi: %sx\n", m.PPI, targetDPI, config.FormatScale(m.Recommended))
+	}
+	s += "\n[c] Custom  [r] Recommended  [1/2] Quick Select  [Esc] Cancel"
 
 	return s
 }
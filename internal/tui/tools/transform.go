@@ -2,6 +2,9 @@ package tools
 
 import (
 	"fmt"
+	"strings"
+
+	"mangomon/config"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -17,6 +20,9 @@ type TransformPickerModel struct {
 	Monitor   string
 	Selected  int
 	CurrentID int
+
+	Width, Height int // Mode size before the transform
+	Scale         float64
 }
 
 var transformNames = []string{
@@ -30,11 +36,14 @@ var transformNames = []string{
 	"Flipped 270° (7)",
 }
 
-func NewTransformPicker(monitor string, currentVal int) TransformPickerModel {
+func NewTransformPicker(monitor string, currentVal, width, height int, scale float64) TransformPickerModel {
 	return TransformPickerModel{
 		Monitor:   monitor,
 		Selected:  currentVal,
 		CurrentID: currentVal,
+		Width:     width,
+		Height:    height,
+		Scale:     scale,
 	}
 }
 
@@ -69,6 +78,7 @@ func (m TransformPickerModel) View() string {
 	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
 	normalStyle := lipgloss.NewStyle().PaddingLeft(2)
 
+	var list string
 	for i, name := range transformNames {
 		cursor := "  "
 		if i == m.Selected {
@@ -81,13 +91,91 @@ func (m TransformPickerModel) View() string {
 		}
 
 		if i == m.Selected {
-			s += selectedStyle.Render(cursor+line) + "\n"
+			list += selectedStyle.Render(cursor+line) + "\n"
 		} else {
-			s += normalStyle.Render(line) + "\n"
+			list += normalStyle.Render(line) + "\n"
 		}
 	}
 
-	s += "\n[Enter] Select  [Esc] Cancel"
+	s += lipgloss.JoinHorizontal(lipgloss.Top, list, "    ", m.preview())
+	s += "\n\n[Enter] Select  [Esc] Cancel"
 
 	return s
 }
+
+var orientationNames = map[config.Edge]string{
+	config.EdgeTop:    "upright",
+	config.EdgeRight:  "turned 90° clockwise",
+	config.EdgeBottom: "upside down",
+	config.EdgeLeft:   "turned 90° counter-clockwise",
+}
+
+// preview draws the monitor as it ends up in the layout for the highlighted
+// transform: the picture is always upright (▲), the heavy line is the
+// panel's own top edge, i.e. where its top bezel has to be
+func (m TransformPickerModel) preview() string {
+	rule := config.MonitorRule{Width: m.Width, Height: m.Height, Scale: m.Scale, Transform: m.Selected}
+	w, h := rule.LogicalSize()
+	top := config.PanelTop(m.Selected)
+
+	cols, rows := 20, 7
+	if w < h {
+		cols, rows = 13, 10
+	}
+	cells := make([][]rune, rows)
+	for y := range cells {
+		cells[y] = []rune(strings.Repeat(" ", cols))
+		cells[y][0], cells[y][cols-1] = '│', '│'
+		if top == config.EdgeLeft {
+			cells[y][0] = '┃'
+		}
+		if top == config.EdgeRight {
+			cells[y][cols-1] = '┃'
+		}
+	}
+	for x := 0; x < cols; x++ {
+		cells[0][x], cells[rows-1][x] = '─', '─'
+		if top == config.EdgeTop {
+			cells[0][x] = '━'
+		}
+		if top == config.EdgeBottom {
+			cells[rows-1][x] = '━'
+		}
+	}
+	cells[0][0], cells[0][cols-1] = '┌', '┐'
+	cells[rows-1][0], cells[rows-1][cols-1] = '└', '┘'
+
+	label := []string{"▲", "up", "", fmt.Sprintf("%dx%d", w, h)}
+	if config.Flipped(m.Selected) {
+		label[1] = "qu" // "up" in a mirror
+	}
+	y0 := (rows - len(label)) / 2
+	for i, text := range label {
+		r := []rune(text)
+		x0 := (cols - len(r)) / 2
+		for j, c := range r {
+			if x0+j > 0 && x0+j < cols-1 {
+				cells[y0+i][x0+j] = c
+			}
+		}
+	}
+
+	var lines []string
+	for _, row := range cells {
+		lines = append(lines, string(row))
+	}
+
+	orientation := orientationNames[top]
+	if config.Flipped(m.Selected) {
+		orientation += ", mirrored"
+	}
+	heavy := "━"
+	if top == config.EdgeLeft || top == config.EdgeRight {
+		heavy = "┃"
+	}
+	lines = append(lines, "",
+		fmt.Sprintf("%s panel top edge: %s", heavy, top),
+		fmt.Sprintf("Monitor %s", orientation),
+		fmt.Sprintf("Layout size: %dx%d", w, h))
+	return strings.Join(lines, "\n")
+}
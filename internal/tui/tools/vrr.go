@@ -13,10 +13,23 @@ type VRRSelectedMsg struct {
 
 type VRRCancelledMsg struct{}
 
+// VRRCapability is what is known about a monitor's adaptive sync support
+type VRRCapability struct {
+	Known            bool // False if neither the driver nor the EDID could be read
+	Capable          bool
+	Likely           bool // Only the EDID says so
+	MinRate, MaxRate float64
+	Source           string // "driver" or "EDID"
+}
+
 type VRRPickerModel struct {
-	Monitor   string
-	Selected  int
-	CurrentID int
+	Monitor    string
+	Selected   int
+	CurrentID  int
+	Capability VRRCapability
+	Rate       float64 // Refresh rate of the current mode
+
+	confirm bool // Enable was chosen once on an unsupported output
 }
 
 var vrrNames = []string{
@@ -24,15 +37,17 @@ var vrrNames = []string{
 	"Enabled (1)",
 }
 
-func NewVRRPicker(monitor string, currentVal int) VRRPickerModel {
+func NewVRRPicker(monitor string, currentVal int, capability VRRCapability, rate float64) VRRPickerModel {
 	selected := currentVal
 	if selected < 0 || selected >= len(vrrNames) {
 		selected = 0
 	}
 	return VRRPickerModel{
-		Monitor:   monitor,
-		Selected:  selected,
-		CurrentID: currentVal,
+		Monitor:    monitor,
+		Selected:   selected,
+		CurrentID:  currentVal,
+		Capability: capability,
+		Rate:       rate,
 	}
 }
 
@@ -50,11 +65,19 @@ func (m VRRPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.Selected > 0 {
 				m.Selected--
 			}
+			m.confirm = false
 		case "down", "j":
 			if m.Selected < len(vrrNames)-1 {
 				m.Selected++
 			}
+			m.confirm = false
 		case "enter":
+			// Enabling VRR on a monitor that says it can't do it is harmless
+			// but useless, so make sure it's deliberate
+			if m.Selected == 1 && m.CurrentID != 1 && m.Capability.Known && !m.Capability.Capable && !m.confirm {
+				m.confirm = true
+				return m, nil
+			}
 			return m, func() tea.Msg { return VRRSelectedMsg{VRR: m.Selected} }
 		}
 	}
@@ -66,6 +89,29 @@ func (m VRRPickerModel) View() string {
 
 	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("220")).Bold(true)
 	normalStyle := lipgloss.NewStyle().PaddingLeft(2)
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+
+	c := m.Capability
+	switch {
+	case !c.Known:
+		s += "Support: unknown (no EDID)\n\n"
+	case !c.Capable:
+		s += fmt.Sprintf("Support: not supported (%s)\n\n", c.Source)
+	case c.MaxRate > 0:
+		likely := ""
+		if c.Likely {
+			likely = "likely, "
+		}
+		s += fmt.Sprintf("Support: %s%.0f-%.0f Hz (%s)\n", likely, c.MinRate, c.MaxRate, c.Source)
+		if m.Rate > c.MaxRate+0.5 || m.Rate < c.MinRate {
+			s += warnStyle.Render(fmt.Sprintf("The current mode (%.2f Hz) is outside this range", m.Rate)) + "\n"
+		}
+		s += "\n"
+	case c.Likely:
+		s += fmt.Sprintf("Support: likely, range unknown (%s)\n\n", c.Source)
+	default:
+		s += fmt.Sprintf("Support: yes, range unknown (%s)\n\n", c.Source)
+	}
 
 	for i, name := range vrrNames {
 		cursor := "  "
@@ -85,6 +131,10 @@ func (m VRRPickerModel) View() string {
 		}
 	}
 
+	if m.confirm {
+		s += "\n" + warnStyle.Render(fmt.Sprintf("%s does not report adaptive sync support. Press Enter again to enable anyway.", m.Monitor)) + "\n"
+	}
+
 	s += "\n[Enter] Select  [Esc] Cancel"
 
 	return s
@@ -3,21 +3,61 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"mangomon/config"
+	"mangomon/internal/cli"
 	"mangomon/internal/tui"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
+	args := os.Args[1:]
+
+	// --replay <dir> runs anything below against a capture
+	if len(args) > 0 && (args[0] == "--replay" || strings.HasPrefix(args[0], "--replay=")) {
+		dir, ok := strings.CutPrefix(args[0], "--replay=")
+		args = args[1:]
+		if !ok {
+			if len(args) == 0 {
+				fmt.Fprintln(os.Stderr, "Usage: mangomon --replay <dir> [command]")
+				os.Exit(2)
+			}
+			dir, args = args[0], args[1:]
+		}
+		if err := cli.StartReplay(dir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "check":
+			os.Exit(cli.Check(args[1:]))
+		case "lint":
+			os.Exit(cli.Lint(args[1:]))
+		case "import":
+			os.Exit(cli.Import(args[1:]))
+		case "export":
+			os.Exit(cli.Export(args[1:]))
+		case "switch":
+			os.Exit(cli.Switch(args[1:]))
+		case "doctor":
+			os.Exit(cli.Doctor(args[1:]))
+		case "capture":
+			os.Exit(cli.Capture(args[1:]))
+		}
+	}
+
 	parser, err := config.NewParser("")
 	if err != nil {
 		fmt.Printf("Error initializing parser: %v\n", err)
 		os.Exit(1)
 	}
 
-	p := tea.NewProgram(tui.InitialModel(parser))
+	p := tea.NewProgram(tui.InitialModel(parser), tea.WithMouseCellMotion())
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)